@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/syou6162/notion-to-md/internal/atomicfile"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+// downloadAttachments downloads the attachments of a page into dir.
+// Attachments that were downloaded before are kept, since their names
+// include the block they belong to.
+func downloadAttachments(ctx context.Context, client *http.Client, attachments []notiontomd.Attachment, dir string) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+
+	for _, attachment := range attachments {
+		path := filepath.Join(dir, attachment.Name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := downloadFile(ctx, client, attachment.URL, path); err != nil {
+			return fmt.Errorf("failed to download %s: %w", attachment.Name, err)
+		}
+	}
+	return nil
+}
+
+// downloadFile writes the content at url to path
+func downloadFile(ctx context.Context, client *http.Client, url string, path string) error {
+	data, err := fetchURL(ctx, client, url)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data, 0o644)
+}
+
+// fetchURL returns the content at url
+func fetchURL(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+func TestDownloadAttachments(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("image data"))
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "attachments")
+	attachments := []notiontomd.Attachment{{URL: server.URL + "/image.png", Name: "image-0c1d2e3f.png"}}
+
+	if err := downloadAttachments(context.Background(), server.Client(), attachments, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "image-0c1d2e3f.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "image data" {
+		t.Errorf("Expected %q, got %q", "image data", string(data))
+	}
+
+	// Existing attachments are not downloaded again
+	if err := downloadAttachments(context.Background(), server.Client(), attachments, dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+
+	missing := []notiontomd.Attachment{{URL: server.URL + "/missing.png", Name: "missing.png"}}
+	if err := downloadAttachments(context.Background(), server.Client(), missing, dir); err == nil {
+		t.Error("Expected an error for a failed download")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing.png")); !os.IsNotExist(err) {
+		t.Error("Expected no file for a failed download")
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+// batchResult is the outcome of converting one input of a batch
+type batchResult struct {
+	Input string
+	Path  string
+	Err   error
+}
+
+// collectInputs returns the page IDs or URLs to convert. They are read one
+// per line from stdin when the only argument is "-", or when there are no
+// arguments and stdin is not a terminal.
+func collectInputs(args []string, stdin *os.File) ([]string, error) {
+	fromStdin := len(args) == 1 && args[0] == "-"
+	if len(args) == 0 {
+		if stat, err := stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice == 0 {
+			fromStdin = true
+		}
+	}
+	if !fromStdin {
+		return args, nil
+	}
+	return readInputs(stdin)
+}
+
+// readInputs reads one input per line, skipping blank lines and # comments
+func readInputs(r io.Reader) ([]string, error) {
+	var inputs []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		inputs = append(inputs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read inputs: %w", err)
+	}
+	return inputs, nil
+}
+
+// convertBatch converts every input into dir concurrently and returns the
+// results in input order. Output paths are claimed once the metadata of
+// every page is known, so pages with the same title are named the same way
+// in every run.
+func convertBatch(ctx context.Context, exporter *pageExporter, inputs []string, dir string) []batchResult {
+	results := make([]batchResult, len(inputs))
+	infos := make([]notiontomd.PageInfo, len(inputs))
+
+	var wg sync.WaitGroup
+	for i, input := range inputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].Input = input
+
+			pageID, err := notiontomd.ExtractBlockID(input)
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+			infos[i], results[i].Err = exporter.pageInfo(ctx, pageID)
+		}()
+	}
+	wg.Wait()
+
+	var found []int
+	var pages []notiontomd.PageInfo
+	for i := range results {
+		if results[i].Err == nil {
+			found = append(found, i)
+			pages = append(pages, infos[i])
+		}
+	}
+	paths := exporter.claimPaths(dir, pages)
+
+	for j, i := range found {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := exporter.export(ctx, infos[i], paths[j])
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+			results[i].Path = result.Path
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// reportBatch writes one line per result followed by a summary and returns
+// the number of failed inputs
+func reportBatch(w io.Writer, results []batchResult) int {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Fprintf(w, "FAIL %s: %v\n", r.Input, r.Err)
+			continue
+		}
+		fmt.Fprintf(w, "ok   %s -> %s\n", r.Input, r.Path)
+	}
+	fmt.Fprintf(w, "%d succeeded, %d failed\n", len(results)-failed, failed)
+	return failed
+}
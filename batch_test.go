@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadInputs(t *testing.T) {
+	input := "cec15681-9083-4e1f-a0ae-72d268507aab\n" +
+		"\n" +
+		"# comment\n" +
+		"  https://www.notion.so/workspace/Page-cec1568190834e1fa0ae72d268507aab  \n"
+
+	result, err := readInputs(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"cec15681-9083-4e1f-a0ae-72d268507aab",
+		"https://www.notion.so/workspace/Page-cec1568190834e1fa0ae72d268507aab",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestCollectInputsFromArgs(t *testing.T) {
+	args := []string{"page-1", "page-2"}
+
+	result, err := collectInputs(args, os.Stdin)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(result, args) {
+		t.Errorf("Expected %v, got %v", args, result)
+	}
+}
+
+func TestCollectInputsFromStdin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inputs.txt")
+	if err := os.WriteFile(path, []byte("page-1\npage-2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+
+	result, err := collectInputs([]string{"-"}, stdin)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"page-1", "page-2"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestReportBatch(t *testing.T) {
+	results := []batchResult{
+		{Input: "page-1", Path: "docs/first.md"},
+		{Input: "page-2", Err: errors.New("not found")},
+		{Input: "page-3", Path: "docs/third.md"},
+	}
+
+	var buf bytes.Buffer
+	failed := reportBatch(&buf, results)
+
+	if failed != 1 {
+		t.Errorf("Expected 1 failure, got %d", failed)
+	}
+
+	expected := "ok   page-1 -> docs/first.md\n" +
+		"FAIL page-2: not found\n" +
+		"ok   page-3 -> docs/third.md\n" +
+		"2 succeeded, 1 failed\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,500 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = ""
+
+// command describes a CLI subcommand
+type command struct {
+	name        string
+	usage       string
+	summary     string
+	description string
+	examples    []string
+	// setup registers the command's flags and returns the function that runs it
+	setup func(fs *flag.FlagSet) func(ctx context.Context, args []string) error
+}
+
+// commands lists the subcommands in the order shown in the help text
+var commands = []*command{
+	pageCommand,
+	databaseCommand,
+	treeCommand,
+	epubCommand,
+	searchCommand,
+	importCommand,
+	diffCommand,
+	syncCommand,
+	watchCommand,
+	serveCommand,
+	versionCommand,
+}
+
+// errUsage is returned by commands that were called with invalid arguments
+var errUsage = errors.New("invalid usage")
+
+// exitError makes run exit with code, reporting err when it is set
+type exitError struct {
+	code int
+	err  error
+}
+
+// Error implements error
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+// Unwrap returns the reported error
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// run executes the CLI with the given arguments and returns the exit code
+func run(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return 2
+	}
+
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			if cmd := findCommand(args[1]); cmd != nil {
+				fs := newFlagSet(cmd)
+				fs.SetOutput(os.Stdout)
+				cmd.setup(fs)
+				fs.Usage()
+				return 0
+			}
+			fmt.Fprintf(os.Stderr, "Error: unknown command %q\n\n", args[1])
+			printUsage(os.Stderr)
+			return 2
+		}
+		printUsage(os.Stdout)
+		return 0
+	}
+
+	cmd := findCommand(name)
+	if cmd != nil {
+		args = args[1:]
+	} else {
+		// A bare ID, URL or flag keeps the original single-page behavior
+		cmd = pageCommand
+	}
+
+	fs := newFlagSet(cmd)
+	runCmd := cmd.setup(fs)
+	positional, err := parseInterspersed(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+
+	if err := runCmd(context.Background(), positional); err != nil {
+		if errors.Is(err, errUsage) {
+			fs.Usage()
+			return 2
+		}
+		var exit *exitError
+		if errors.As(err, &exit) {
+			if exit.err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", exit.err)
+			}
+			return exit.code
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// findCommand returns the subcommand with the given name, or nil
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// newFlagSet creates the flag set of a command with its help text. The
+// command's flags are registered by its setup function.
+func newFlagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		w := fs.Output()
+		fmt.Fprintf(w, "Usage: notion-to-md %s\n\n", cmd.usage)
+		fmt.Fprintln(w, cmd.description)
+		if len(cmd.examples) > 0 {
+			fmt.Fprintln(w, "")
+			fmt.Fprintln(w, "Examples:")
+			for _, example := range cmd.examples {
+				fmt.Fprintln(w, "  "+example)
+			}
+		}
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "Flags:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// printUsage prints the top-level help text
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: notion-to-md <command> [flags] [arguments]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "A page ID or URL without a command is converted with 'page'.")
+	fmt.Fprintln(w, "Run 'notion-to-md help <command>' for the flags of a command.")
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments and returns the positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// versionString returns the version set at build time, or the module
+// version recorded by go install
+func versionString() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// tokenOptions holds the flags selecting where the Notion token comes from
+type tokenOptions struct {
+	token     string
+	tokenFile string
+	tokenEnv  string
+}
+
+// register adds the token flags to the flag set
+func (o *tokenOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.token, "token", "", "Notion integration token (prefer --token-file or --token-env)")
+	fs.StringVar(&o.tokenFile, "token-file", "", "read the Notion integration token from `FILE`")
+	fs.StringVar(&o.tokenEnv, "token-env", "NOTION_TOKEN", "read the Notion integration token from the environment variable `NAME`")
+}
+
+// resolve returns the token from the flag, the token file or the environment, in that order
+func (o *tokenOptions) resolve() (string, error) {
+	if o.token != "" {
+		return o.token, nil
+	}
+	if o.tokenFile != "" {
+		data, err := os.ReadFile(o.tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %w", err)
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("token file %s is empty", o.tokenFile)
+		}
+		return token, nil
+	}
+	token := os.Getenv(o.tokenEnv)
+	if token == "" {
+		return "", fmt.Errorf("%s environment variable not set", o.tokenEnv)
+	}
+	return token, nil
+}
+
+// newClient creates a Notion client with the configured token
+func (o *tokenOptions) newClient() (*notionapi.Client, error) {
+	token, err := o.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return notionapi.NewClient(notionapi.Token(token)), nil
+}
+
+// outputOptions holds the flags selecting where exported pages are written
+type outputOptions struct {
+	outDir string
+	slug   string
+}
+
+// register adds the output flags to the flag set
+func (o *outputOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.outDir, "out-dir", "", "write each page into `DIR` with a file name derived from the page")
+	fs.StringVar(&o.slug, "slug", string(slugTitle), "file name `strategy` for --out-dir: title, ascii, title-id or id")
+}
+
+// dir returns the output directory of multi-page exports
+func (o *outputOptions) dir() string {
+	if o.outDir == "" {
+		return "."
+	}
+	return o.outDir
+}
+
+// slugStrategy returns the validated slug strategy
+func (o *outputOptions) slugStrategy() (slugStrategy, error) {
+	return parseSlugStrategy(o.slug)
+}
+
+// cacheOptions holds the flags of the on-disk block cache
+type cacheOptions struct {
+	noCache bool
+	dir     string
+	ttl     time.Duration
+}
+
+// register adds the cache flags to the flag set
+func (o *cacheOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.noCache, "no-cache", false, "always fetch blocks from the API instead of the on-disk cache")
+	fs.StringVar(&o.dir, "cache-dir", "", "store cached blocks in `DIR` (default: notion-to-md in the user cache directory)")
+	fs.DurationVar(&o.ttl, "cache-ttl", notiontomd.DefaultCacheTTL, "use cached blocks for at most this long (0 for no limit)")
+}
+
+// open returns the configured cache, or nil when caching is disabled
+func (o *cacheOptions) open() (*notiontomd.BlockCache, error) {
+	if o.noCache {
+		return nil, nil
+	}
+	if o.ttl < 0 {
+		return nil, fmt.Errorf("--cache-ttl must not be negative")
+	}
+	dir := o.dir
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate cache directory (use --cache-dir or --no-cache): %w", err)
+		}
+		dir = filepath.Join(userCacheDir, "notion-to-md")
+	}
+	return notiontomd.NewBlockCache(dir, o.ttl), nil
+}
+
+// renderOptions holds the flags controlling how pages are fetched and rendered
+type renderOptions struct {
+	format        string
+	noFrontMatter bool
+	css           bool
+	linkURLs      bool
+	preset        string
+	properties    propertyNames
+	attachments   string
+	mdxComponents string
+	slideBreak    string
+	// components is the parsed --mdx-components file, set by validate
+	components *notiontomd.MDXComponents
+	depth      int
+	truncate   bool
+}
+
+// propertyNames collects repeated --property field=Name flags
+type propertyNames map[string]string
+
+// String implements flag.Value
+func (p propertyNames) String() string {
+	fields := make([]string, 0, len(p))
+	for field := range p {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	pairs := make([]string, len(fields))
+	for i, field := range fields {
+		pairs[i] = field + "=" + p[field]
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set implements flag.Value
+func (p propertyNames) Set(value string) error {
+	field, name, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected field=Property, got %q", value)
+	}
+	if _, known := notiontomd.DefaultPropertyNames[field]; !known {
+		return fmt.Errorf("unknown front matter field %q", field)
+	}
+	p[field] = name
+	return nil
+}
+
+// register adds the render flags to the flag set
+func (o *renderOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "format", "markdown", "output `format`: markdown, mdx, html, org, asciidoc, text or json")
+	fs.BoolVar(&o.noFrontMatter, "no-front-matter", false, "omit the front matter or document header (for html, write a fragment without <html> and <head>)")
+	fs.BoolVar(&o.css, "css", false, "embed minimal CSS in html output")
+	fs.BoolVar(&o.linkURLs, "link-urls", false, "append link URLs in parentheses in text output")
+	fs.StringVar(&o.preset, "preset", "", "adapt markdown output to a static site generator, blog, vault or slide deck: hugo, jekyll, docusaurus, zola, zenn, qiita, obsidian, hatena, hatena-atompub, marp or revealjs")
+	o.properties = propertyNames{}
+	fs.Var(o.properties, "property", "read a front matter `field=Property` (date, draft, slug, tags, weight or aliases) from another page property; repeatable")
+	fs.StringVar(&o.mdxComponents, "mdx-components", "", "read the JSX components of mdx output from the JSON `FILE`")
+	fs.StringVar(&o.slideBreak, "slide-break", string(notiontomd.SlideBreakAuto), "start marp and revealjs slides at each `KIND` of block: divider, heading (heading_1 and heading_2) or auto")
+	fs.StringVar(&o.attachments, "attachments", "attachments", "download files embedded in obsidian output into `DIR` (relative to the output directory)")
+	fs.IntVar(&o.depth, "depth", notiontomd.DefaultMaxDepth, "maximum nesting depth to fetch (0 for unlimited)")
+	fs.BoolVar(&o.truncate, "truncate", false, "omit blocks nested deeper than --depth instead of failing")
+}
+
+// outputFormat returns the converter format selected by --format
+func (o *renderOptions) outputFormat() (notiontomd.Format, error) {
+	switch o.format {
+	case "markdown", "md":
+		return notiontomd.FormatMarkdown, nil
+	case "mdx":
+		return notiontomd.FormatMDX, nil
+	case "html":
+		return notiontomd.FormatHTML, nil
+	case "org":
+		return notiontomd.FormatOrg, nil
+	case "asciidoc", "adoc":
+		return notiontomd.FormatAsciiDoc, nil
+	case "text", "txt":
+		return notiontomd.FormatText, nil
+	case "json":
+		return notiontomd.FormatJSON, nil
+	}
+	return "", fmt.Errorf("unsupported format %q", o.format)
+}
+
+// validate checks the flag values before anything is fetched
+func (o *renderOptions) validate() error {
+	if _, err := o.outputFormat(); err != nil {
+		return err
+	}
+	if o.preset != "" {
+		if notiontomd.LookupPreset(o.preset) == nil {
+			return fmt.Errorf("unknown preset %q", o.preset)
+		}
+		if format, _ := o.outputFormat(); format != notiontomd.FormatMarkdown {
+			return fmt.Errorf("--preset requires markdown output")
+		}
+	}
+	switch notiontomd.SlideBreak(o.slideBreak) {
+	case notiontomd.SlideBreakAuto, notiontomd.SlideBreakDivider, notiontomd.SlideBreakHeading:
+	default:
+		return fmt.Errorf("unsupported slide break %q", o.slideBreak)
+	}
+	if o.mdxComponents != "" {
+		data, err := os.ReadFile(o.mdxComponents)
+		if err != nil {
+			return fmt.Errorf("failed to read MDX components: %w", err)
+		}
+		if o.components, err = notiontomd.ParseMDXComponents(data); err != nil {
+			return err
+		}
+	}
+	if o.depth < 0 {
+		return fmt.Errorf("--depth must not be negative")
+	}
+	return nil
+}
+
+// newConverter creates a converter configured by the flags. names gives the
+// files that links between exported pages point to.
+func (o *renderOptions) newConverter(client *notionapi.Client, cache *notiontomd.BlockCache, names *pageNames) *notiontomd.Converter {
+	format, _ := o.outputFormat()
+	preset := notiontomd.LookupPreset(o.preset)
+	switch preset {
+	case notiontomd.Obsidian:
+		preset = notiontomd.NewObsidian(notiontomd.ObsidianOptions{
+			LinkTarget: names.linkTarget,
+		})
+	case notiontomd.Marp:
+		preset = notiontomd.NewMarp(notiontomd.SlideOptions{Break: notiontomd.SlideBreak(o.slideBreak)})
+	case notiontomd.RevealJS:
+		preset = notiontomd.NewRevealJS(notiontomd.SlideOptions{Break: notiontomd.SlideBreak(o.slideBreak)})
+	}
+	return notiontomd.New(client, notiontomd.Options{
+		Format:   format,
+		MaxDepth: o.depth,
+		Truncate: o.truncate,
+		OnTruncate: func(blockID notionapi.BlockID, _ int) {
+			fmt.Fprintf(os.Stderr, "Warning: block %s has children nested deeper than %d levels; truncated\n", blockID, o.depth)
+		},
+		OmitFrontMatter:   o.noFrontMatter,
+		HTMLStyle:         o.css,
+		LinkURLs:          o.linkURLs,
+		Preset:            preset,
+		PropertyNames:     o.properties,
+		MDXComponents:     o.components,
+		ResolveLinkTitles: o.preset == notiontomd.Obsidian.Name,
+		Cache:             cache,
+	})
+}
+
+// fingerprint summarizes the options that affect the rendered output
+func (o *renderOptions) fingerprint() string {
+	format, _ := o.outputFormat()
+	return fmt.Sprintf("format=%s front-matter=%t css=%t link-urls=%t preset=%s properties=%s slide-break=%s attachments=%s mdx-components=%s depth=%d truncate=%t", format, !o.noFrontMatter, o.css, o.linkURLs, o.preset, o.properties, o.slideBreak, o.attachmentDir(""), o.mdxComponents, o.depth, o.truncate)
+}
+
+// attachmentDir returns the directory under root that attachments are
+// downloaded into, or "" when the output does not embed attachments
+func (o *renderOptions) attachmentDir(root string) string {
+	if o.preset != notiontomd.Obsidian.Name {
+		return ""
+	}
+	if filepath.IsAbs(o.attachments) {
+		return o.attachments
+	}
+	return filepath.Join(root, o.attachments)
+}
+
+// extension returns the file extension of the selected output format
+func (o *renderOptions) extension() string {
+	if o.preset == notiontomd.HatenaAtomPub.Name {
+		return ".xml"
+	}
+	format, _ := o.outputFormat()
+	switch format {
+	case notiontomd.FormatMDX:
+		return ".mdx"
+	case notiontomd.FormatHTML:
+		return ".html"
+	case notiontomd.FormatOrg:
+		return ".org"
+	case notiontomd.FormatAsciiDoc:
+		return ".adoc"
+	case notiontomd.FormatText:
+		return ".txt"
+	case notiontomd.FormatJSON:
+		return ".json"
+	}
+	return ".md"
+}
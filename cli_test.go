@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseInterspersed(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	output := fs.String("o", "", "")
+	truncate := fs.Bool("truncate", false, "")
+
+	positional, err := parseInterspersed(fs, []string{"first", "-o", "out.md", "second", "--truncate"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"first", "second"}
+	if !reflect.DeepEqual(positional, expected) {
+		t.Errorf("Expected positional %v, got %v", expected, positional)
+	}
+	if *output != "out.md" {
+		t.Errorf("Expected output %q, got %q", "out.md", *output)
+	}
+	if !*truncate {
+		t.Error("Expected truncate to be set")
+	}
+}
+
+func TestFindCommand(t *testing.T) {
+	for _, name := range []string{"page", "database", "tree", "search", "sync", "version"} {
+		if findCommand(name) == nil {
+			t.Errorf("Expected command %q to exist", name)
+		}
+	}
+
+	if findCommand("cec15681-9083-4e1f-a0ae-72d268507aab") != nil {
+		t.Error("Expected a page ID not to match a command")
+	}
+}
+
+func TestTokenOptionsResolve(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_NOTION_TOKEN", "from-env")
+
+	tests := []struct {
+		name     string
+		opts     tokenOptions
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "Flag takes precedence",
+			opts:     tokenOptions{token: "from-flag", tokenFile: tokenFile, tokenEnv: "TEST_NOTION_TOKEN"},
+			expected: "from-flag",
+		},
+		{
+			name:     "Token file is trimmed",
+			opts:     tokenOptions{tokenFile: tokenFile, tokenEnv: "TEST_NOTION_TOKEN"},
+			expected: "from-file",
+		},
+		{
+			name:     "Environment variable",
+			opts:     tokenOptions{tokenEnv: "TEST_NOTION_TOKEN"},
+			expected: "from-env",
+		},
+		{
+			name:    "Missing environment variable",
+			opts:    tokenOptions{tokenEnv: "TEST_NOTION_TOKEN_UNSET"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.opts.resolve()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRenderOptionsPreset(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var render renderOptions
+	render.register(fs)
+
+	if err := fs.Parse([]string{"--preset", "hugo", "--property", "date=Published", "--property", "draft=Status"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := render.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := propertyNames{"date": "Published", "draft": "Status"}
+	if !reflect.DeepEqual(render.properties, expected) {
+		t.Errorf("Expected %v, got %v", expected, render.properties)
+	}
+	if got := render.properties.String(); got != "date=Published,draft=Status" {
+		t.Errorf("Expected %q, got %q", "date=Published,draft=Status", got)
+	}
+
+	if err := render.properties.Set("author=Owner"); err == nil {
+		t.Error("Expected an error for an unknown front matter field")
+	}
+
+	render.format = "html"
+	if err := render.validate(); err == nil {
+		t.Error("Expected --preset to require markdown output")
+	}
+
+	render.format = "markdown"
+	render.slideBreak = "page"
+	if err := render.validate(); err == nil {
+		t.Error("Expected an error for an unknown slide break")
+	}
+}
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+// defaultConcurrency is the number of pages fetched in parallel by default
+const defaultConcurrency = 4
+
+var pageCommand = &command{
+	name:    "page",
+	usage:   "page [flags] <page-id-or-url>... | -",
+	summary: "Convert one or more pages",
+	description: "Fetch a Notion page and write it to stdout, to the file given with -o, or into --out-dir\n" +
+		"with a file name derived from the page title.\n\n" +
+		"Several pages can be given as arguments, or one per line on stdin with \"-\" or when stdin is\n" +
+		"not a terminal. They are converted concurrently, each into its own file in --out-dir (default\n" +
+		"the current directory), and the result of each page is reported on stderr.",
+	examples: []string{
+		"notion-to-md page cec15681-9083-4e1f-a0ae-72d268507aab",
+		"notion-to-md page https://www.notion.so/10xall/By-name-cec1568190834e1fa0ae72d268507aab",
+		"notion-to-md page -o page.md --depth 3 --truncate cec15681-9083-4e1f-a0ae-72d268507aab",
+		"notion-to-md page --out-dir docs --slug ascii cec15681-9083-4e1f-a0ae-72d268507aab",
+		"notion-to-md page --out-dir docs - < pages.txt",
+	},
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var tokens tokenOptions
+		var render renderOptions
+		var cacheOpts cacheOptions
+		var output outputOptions
+		var outFile string
+		var concurrency int
+		tokens.register(fs)
+		render.register(fs)
+		cacheOpts.register(fs)
+		output.register(fs)
+		fs.StringVar(&outFile, "o", "", "write the output to `FILE` instead of stdout")
+		fs.IntVar(&concurrency, "concurrency", defaultConcurrency, "number of pages fetched in parallel")
+
+		return func(ctx context.Context, args []string) error {
+			inputs, err := collectInputs(args, os.Stdin)
+			if err != nil {
+				return err
+			}
+			if len(inputs) == 0 {
+				return errUsage
+			}
+			if err := render.validate(); err != nil {
+				return err
+			}
+			if concurrency < 1 {
+				return errors.New("--concurrency must be at least 1")
+			}
+			slug, err := output.slugStrategy()
+			if err != nil {
+				return err
+			}
+			if outFile != "" && output.outDir != "" {
+				return errors.New("-o and --out-dir cannot be used together")
+			}
+			if outFile != "" && len(inputs) > 1 {
+				return errors.New("-o cannot be used with multiple pages; use --out-dir")
+			}
+
+			client, err := tokens.newClient()
+			if err != nil {
+				return err
+			}
+			cache, err := cacheOpts.open()
+			if err != nil {
+				return err
+			}
+
+			if len(inputs) > 1 {
+				names := newPageNames(slug, render.extension())
+				exporter := newPageExporter(render.newConverter(client, cache, names), names, concurrency, false)
+				exporter.attachments = render.attachmentDir(output.dir())
+				results := convertBatch(ctx, exporter, inputs, output.dir())
+				if failed := reportBatch(os.Stderr, results); failed > 0 {
+					return fmt.Errorf("%d of %d pages failed", failed, len(results))
+				}
+				return nil
+			}
+
+			doc, err := render.newConverter(client, cache, newPageNames(slug, render.extension())).ConvertPage(ctx, inputs[0])
+			if err != nil {
+				return err
+			}
+			if output.outDir != "" {
+				outFile = filepath.Join(output.outDir, pageSlug(doc.Info, slug)+render.extension())
+			}
+			if err := writeOutput(outFile, doc.Content); err != nil {
+				return err
+			}
+			if dir := render.attachmentDir(filepath.Dir(outFile)); dir != "" {
+				return downloadAttachments(ctx, http.DefaultClient, notiontomd.Attachments(doc.Nodes), dir)
+			}
+			return nil
+		}
+	},
+}
+
+var databaseCommand = &command{
+	name:        "database",
+	usage:       "database [flags] <database-id-or-url>",
+	summary:     "Convert every page in a database",
+	description: "Query a Notion database and write each of its pages as a separate file into --out-dir.",
+	examples: []string{
+		"notion-to-md database --out-dir docs 1b2c3d4e5f60718293a4b5c6d7e8f901",
+	},
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var tokens tokenOptions
+		var render renderOptions
+		var cacheOpts cacheOptions
+		var output outputOptions
+		var concurrency int
+		tokens.register(fs)
+		render.register(fs)
+		cacheOpts.register(fs)
+		output.register(fs)
+		fs.IntVar(&concurrency, "concurrency", defaultConcurrency, "number of pages fetched in parallel")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return errUsage
+			}
+			if err := render.validate(); err != nil {
+				return err
+			}
+			if concurrency < 1 {
+				return errors.New("--concurrency must be at least 1")
+			}
+			slug, err := output.slugStrategy()
+			if err != nil {
+				return err
+			}
+
+			databaseID, err := notiontomd.ExtractBlockID(args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := tokens.newClient()
+			if err != nil {
+				return err
+			}
+			cache, err := cacheOpts.open()
+			if err != nil {
+				return err
+			}
+
+			pages, err := queryDatabasePages(ctx, client, notionapi.DatabaseID(databaseID))
+			if err != nil {
+				return err
+			}
+
+			names := newPageNames(slug, render.extension())
+			exporter := newPageExporter(render.newConverter(client, cache, names), names, concurrency, false)
+			exporter.attachments = render.attachmentDir(output.dir())
+			exporter.add(ctx, nil, pages, output.dir())
+			return exporter.wait()
+		}
+	},
+}
+
+var treeCommand = &command{
+	name:        "tree",
+	usage:       "tree [flags] <page-id-or-url>",
+	summary:     "Convert a page and all of its child pages",
+	description: "Fetch a page and its child pages recursively and write each page as a separate file into --out-dir.\nChild pages are written into a directory named after their parent.",
+	examples: []string{
+		"notion-to-md tree --out-dir handbook cec15681-9083-4e1f-a0ae-72d268507aab",
+	},
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var tokens tokenOptions
+		var render renderOptions
+		var cacheOpts cacheOptions
+		var output outputOptions
+		var concurrency int
+		tokens.register(fs)
+		render.register(fs)
+		cacheOpts.register(fs)
+		output.register(fs)
+		fs.IntVar(&concurrency, "concurrency", defaultConcurrency, "number of pages fetched in parallel")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return errUsage
+			}
+			if err := render.validate(); err != nil {
+				return err
+			}
+			if concurrency < 1 {
+				return errors.New("--concurrency must be at least 1")
+			}
+			slug, err := output.slugStrategy()
+			if err != nil {
+				return err
+			}
+
+			pageID, err := notiontomd.ExtractBlockID(args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := tokens.newClient()
+			if err != nil {
+				return err
+			}
+			cache, err := cacheOpts.open()
+			if err != nil {
+				return err
+			}
+
+			names := newPageNames(slug, render.extension())
+			exporter := newPageExporter(render.newConverter(client, cache, names), names, concurrency, true)
+			exporter.attachments = render.attachmentDir(output.dir())
+			exporter.add(ctx, []notionapi.BlockID{pageID}, nil, output.dir())
+			return exporter.wait()
+		}
+	},
+}
+
+var searchCommand = &command{
+	name:        "search",
+	usage:       "search [flags] [query]",
+	summary:     "Search pages and databases shared with the integration",
+	description: "Search the pages and databases shared with the integration by title and print one\ntab-separated line per result: object type, ID, title and URL.",
+	examples: []string{
+		"notion-to-md search handbook",
+		"notion-to-md search --type database",
+	},
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var tokens tokenOptions
+		var objectType string
+		var limit int
+		tokens.register(fs)
+		fs.StringVar(&objectType, "type", "", "only return objects of `TYPE` (page or database)")
+		fs.IntVar(&limit, "limit", 0, "maximum number of results (0 for all)")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) > 1 {
+				return errUsage
+			}
+			switch objectType {
+			case "", "page", "database":
+			default:
+				return fmt.Errorf("unsupported type %q", objectType)
+			}
+
+			client, err := tokens.newClient()
+			if err != nil {
+				return err
+			}
+
+			request := &notionapi.SearchRequest{}
+			if len(args) == 1 {
+				request.Query = args[0]
+			}
+			if objectType != "" {
+				request.Filter = notionapi.SearchFilter{Property: "object", Value: objectType}
+			}
+
+			var out strings.Builder
+			count := 0
+			for {
+				resp, err := client.Search.Do(ctx, request)
+				if err != nil {
+					return fmt.Errorf("failed to search: %w", err)
+				}
+
+				for _, obj := range resp.Results {
+					if limit > 0 && count >= limit {
+						break
+					}
+					out.WriteString(formatSearchResult(obj) + "\n")
+					count++
+				}
+
+				if !resp.HasMore || (limit > 0 && count >= limit) {
+					break
+				}
+				request.StartCursor = resp.NextCursor
+			}
+			return writeOutput("", out.String())
+		}
+	},
+}
+
+// formatSearchResult formats a search result as a tab-separated line
+func formatSearchResult(obj notionapi.Object) string {
+	switch o := obj.(type) {
+	case *notionapi.Page:
+		info := notiontomd.PageInfoFromPage(o)
+		return strings.Join([]string{"page", string(info.ID), info.Title, info.URL}, "\t")
+	case *notionapi.Database:
+		var title strings.Builder
+		for _, rt := range o.Title {
+			title.WriteString(rt.PlainText)
+		}
+		return strings.Join([]string{"database", string(o.ID), title.String(), o.URL}, "\t")
+	}
+	return string(obj.GetObject())
+}
+
+var syncCommand = &command{
+	name:    "sync",
+	usage:   "sync [flags] <page-or-database-id-or-url>...",
+	summary: "Incrementally export page trees or databases into a directory",
+	description: "Export page trees (or, with --database, databases) into --out-dir and keep a manifest of\n" +
+		"the exported pages in " + manifestFile + ". Pages whose last edited time did not change since\n" +
+		"the last sync are not fetched again. Files of pages that were removed or moved are archived\n" +
+		"into " + archiveDir + ", deleted or kept according to --prune.",
+	examples: []string{
+		"notion-to-md sync --out-dir handbook cec15681-9083-4e1f-a0ae-72d268507aab",
+		"notion-to-md sync --out-dir blog --database --prune delete 1b2c3d4e5f60718293a4b5c6d7e8f901",
+	},
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var tokens tokenOptions
+		var render renderOptions
+		var cacheOpts cacheOptions
+		var output outputOptions
+		var concurrency int
+		var database, force bool
+		var prune string
+		tokens.register(fs)
+		render.register(fs)
+		cacheOpts.register(fs)
+		output.register(fs)
+		fs.IntVar(&concurrency, "concurrency", defaultConcurrency, "number of pages fetched in parallel")
+		fs.BoolVar(&database, "database", false, "treat the arguments as databases instead of page trees")
+		fs.BoolVar(&force, "force", false, "export every page even if it did not change")
+		fs.StringVar(&prune, "prune", string(pruneArchive), "what to do with files of removed or moved pages: archive, delete or keep")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return errUsage
+			}
+			if err := render.validate(); err != nil {
+				return err
+			}
+			if concurrency < 1 {
+				return errors.New("--concurrency must be at least 1")
+			}
+			slug, err := output.slugStrategy()
+			if err != nil {
+				return err
+			}
+			mode, err := parsePruneMode(prune)
+			if err != nil {
+				return err
+			}
+
+			var ids []notionapi.BlockID
+			for _, arg := range args {
+				id, err := notiontomd.ExtractBlockID(arg)
+				if err != nil {
+					return err
+				}
+				ids = append(ids, id)
+			}
+
+			client, err := tokens.newClient()
+			if err != nil {
+				return err
+			}
+			cache, err := cacheOpts.open()
+			if err != nil {
+				return err
+			}
+
+			outDir := output.dir()
+			previous, err := loadManifest(outDir)
+			if err != nil {
+				return err
+			}
+			state := newSyncState(outDir, previous, render.fingerprint()+" slug="+string(slug))
+			state.force = force
+
+			names := newPageNames(slug, render.extension())
+			exporter := newPageExporter(render.newConverter(client, cache, names), names, concurrency, !database)
+			exporter.state = state
+			exporter.attachments = render.attachmentDir(outDir)
+			var queryErrs []error
+			if database {
+				// Pages of all databases share the output directory, so they
+				// are named together
+				var pages []notiontomd.PageInfo
+				for _, id := range ids {
+					found, err := queryDatabasePages(ctx, client, notionapi.DatabaseID(id))
+					if err != nil {
+						queryErrs = append(queryErrs, err)
+						continue
+					}
+					pages = append(pages, found...)
+				}
+				exporter.add(ctx, nil, pages, outDir)
+			} else {
+				exporter.add(ctx, ids, nil, outDir)
+			}
+			exportErr := errors.Join(append(queryErrs, exporter.wait())...)
+
+			// Pages that could not be reached are kept as they are, since
+			// they may still exist in Notion
+			pruned := 0
+			if exportErr != nil {
+				state.keepUnseen()
+			} else if pruned, err = state.prune(mode); err != nil {
+				exportErr = err
+			}
+
+			if err := state.current.save(outDir); err != nil {
+				return errors.Join(exportErr, err)
+			}
+			fmt.Fprintf(os.Stderr, "%d exported, %d unchanged, %d pruned\n", state.exported, state.unchanged, pruned)
+			return exportErr
+		}
+	},
+}
+
+var versionCommand = &command{
+	name:        "version",
+	usage:       "version",
+	summary:     "Print version information",
+	description: "Print the version of notion-to-md.",
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 0 {
+				return errUsage
+			}
+			return writeOutput("", "notion-to-md "+versionString()+"\n")
+		}
+	},
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+// diffContext is the number of unchanged lines shown around changes
+const diffContext = 3
+
+// maxDiffCells bounds the table used to compare the changed middle of two
+// files. Larger rewrites are shown as the old lines removed and the new added.
+const maxDiffCells = 16 << 20
+
+var diffCommand = &command{
+	name:    "diff",
+	usage:   "diff [flags] <page-id-or-url> <FILE>",
+	summary: "Show how a page differs from its exported file",
+	description: "Fetch and convert a page with the same flags as the export, and print a unified diff from\n" +
+		"FILE to the current content. The exit code is 0 when they are the same, 1 when they differ\n" +
+		"and 2 when the page or file cannot be read.",
+	examples: []string{
+		"notion-to-md diff cec15681-9083-4e1f-a0ae-72d268507aab page.md",
+		"notion-to-md diff --ignore-timestamps --preset hugo cec15681-9083-4e1f-a0ae-72d268507aab content/posts/page.md",
+	},
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var tokens tokenOptions
+		var render renderOptions
+		var cacheOpts cacheOptions
+		var slugName string
+		var ignoreTimestamps bool
+		tokens.register(fs)
+		render.register(fs)
+		cacheOpts.register(fs)
+		fs.StringVar(&slugName, "slug", string(slugTitle), "file name `strategy` of links between pages: title, ascii, title-id or id")
+		fs.BoolVar(&ignoreTimestamps, "ignore-timestamps", false, "ignore front matter and header entries whose value is a timestamp, such as created and updated")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 2 {
+				return errUsage
+			}
+			fail := func(err error) error {
+				return &exitError{code: 2, err: err}
+			}
+			if err := render.validate(); err != nil {
+				return fail(err)
+			}
+			if ignoreTimestamps {
+				if err := checkIgnoreTimestamps(&render); err != nil {
+					return fail(err)
+				}
+			}
+			slug, err := parseSlugStrategy(slugName)
+			if err != nil {
+				return fail(err)
+			}
+
+			exported, err := os.ReadFile(args[1])
+			if err != nil {
+				return fail(fmt.Errorf("failed to read %s: %w", args[1], err))
+			}
+
+			client, err := tokens.newClient()
+			if err != nil {
+				return fail(err)
+			}
+			cache, err := cacheOpts.open()
+			if err != nil {
+				return fail(err)
+			}
+			doc, err := render.newConverter(client, cache, newPageNames(slug, render.extension())).ConvertPage(ctx, args[0])
+			if err != nil {
+				return fail(err)
+			}
+
+			old, current := string(exported), doc.Content
+			if ignoreTimestamps {
+				format, _ := render.outputFormat()
+				old, current = stripTimestamps(old, format), stripTimestamps(current, format)
+			}
+			diff := unifiedDiff(args[1], "notion:"+string(doc.Info.ID), old, current)
+			if diff == "" {
+				return nil
+			}
+			if err := writeOutput("", diff); err != nil {
+				return fail(err)
+			}
+			return &exitError{code: 1}
+		}
+	},
+}
+
+// timestampPattern matches an RFC 3339 timestamp
+const timestampPattern = `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:?\d{2})?`
+
+var (
+	// frontMatterTimestamp matches YAML and TOML front matter entries whose value is a timestamp
+	frontMatterTimestamp = regexp.MustCompile(`^\s*[\w.-]+\s*[:=]\s*["']?` + timestampPattern + `["']?\s*$`)
+	// orgTimestamp matches Org-mode keywords whose value is an inactive timestamp, e.g. #+UPDATED:
+	orgTimestamp = regexp.MustCompile(`^#\+\w+:\s*\[\d{4}-\d{2}-\d{2} \w+ \d{2}:\d{2}\]\s*$`)
+	// asciiDocTimestamp matches AsciiDoc attributes whose value is a timestamp, e.g. :revdate:
+	asciiDocTimestamp = regexp.MustCompile(`^:[\w-]+:\s*` + timestampPattern + `\s*$`)
+	// htmlTimestamp matches meta elements whose content is a timestamp, e.g. <meta name="updated">
+	htmlTimestamp = regexp.MustCompile(`^\s*<meta name="[\w-]+" content="` + timestampPattern + `">\s*$`)
+)
+
+// checkIgnoreTimestamps reports an error when the timestamps of the output
+// cannot be told apart from the content, instead of ignoring none
+func checkIgnoreTimestamps(render *renderOptions) error {
+	if format, _ := render.outputFormat(); format == notiontomd.FormatJSON {
+		return errors.New("--ignore-timestamps does not support JSON output")
+	}
+	if preset := notiontomd.LookupPreset(render.preset); preset != nil && preset.Document != nil {
+		return fmt.Errorf("--ignore-timestamps does not support --preset %s", render.preset)
+	}
+	return nil
+}
+
+// stripTimestamps removes the timestamps of the front matter or header of
+// content in format. Plain text has no timestamps.
+func stripTimestamps(content string, format notiontomd.Format) string {
+	switch format {
+	case notiontomd.FormatOrg:
+		return stripHeaderTimestamps(content, func(line string) bool {
+			return strings.HasPrefix(line, "#+")
+		}, orgTimestamp)
+	case notiontomd.FormatAsciiDoc:
+		// The document header ends at the first blank line
+		return stripHeaderTimestamps(content, func(line string) bool {
+			return strings.TrimSpace(line) != ""
+		}, asciiDocTimestamp)
+	case notiontomd.FormatHTML:
+		return stripHeaderTimestamps(content, func(line string) bool {
+			return !strings.HasPrefix(strings.TrimSpace(line), "<body")
+		}, htmlTimestamp)
+	case notiontomd.FormatText:
+		return content
+	}
+	return stripFrontMatterTimestamps(content)
+}
+
+// stripHeaderTimestamps removes the lines matching timestamp from the
+// header of content, i.e. the leading lines for which inHeader is true
+func stripHeaderTimestamps(content string, inHeader func(line string) bool, timestamp *regexp.Regexp) string {
+	var result strings.Builder
+	header := true
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimRight(line, "\n")
+		header = header && inHeader(trimmed)
+		if header && timestamp.MatchString(trimmed) {
+			continue
+		}
+		result.WriteString(line)
+	}
+	return result.String()
+}
+
+// stripFrontMatterTimestamps removes the timestamp entries of a leading
+// front matter delimited by --- (YAML) or +++ (TOML)
+func stripFrontMatterTimestamps(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+	delimiter := strings.TrimSpace(lines[0])
+	if delimiter != "---" && delimiter != "+++" {
+		return content
+	}
+
+	// Without a closing delimiter the first line is e.g. a thematic break,
+	// not front matter
+	end := 0
+	for i := 1; i < len(lines) && end == 0; i++ {
+		if strings.TrimSpace(lines[i]) == delimiter {
+			end = i
+		}
+	}
+	if end == 0 {
+		return content
+	}
+
+	var result strings.Builder
+	for i, line := range lines {
+		if i > 0 && i < end && frontMatterTimestamp.MatchString(strings.TrimRight(line, "\n")) {
+			continue
+		}
+		result.WriteString(line)
+	}
+	return result.String()
+}
+
+// diffLine is a line of an edit script: ' ' kept, '-' removed or '+' added
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// unifiedDiff returns the changes from oldText to newText in the unified
+// format, or "" when they are the same
+func unifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+
+	// oldLine[k] and newLine[k] count the lines before lines[k] in each file
+	oldLine := make([]int, len(lines)+1)
+	newLine := make([]int, len(lines)+1)
+	for k, line := range lines {
+		oldLine[k+1], newLine[k+1] = oldLine[k], newLine[k]
+		if line.op != '+' {
+			oldLine[k+1]++
+		}
+		if line.op != '-' {
+			newLine[k+1]++
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString("--- " + oldName + "\n")
+	result.WriteString("+++ " + newName + "\n")
+	for k := 0; k < len(lines); {
+		for k < len(lines) && lines[k].op == ' ' {
+			k++
+		}
+		if k == len(lines) {
+			break
+		}
+
+		// A hunk ends where more than twice the context separates changes
+		start := max(0, k-diffContext)
+		end := k
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				end = min(end+diffContext, len(lines))
+				break
+			}
+			end = next
+		}
+
+		result.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(oldLine[start], oldLine[end]-oldLine[start]),
+			hunkRange(newLine[start], newLine[end]-newLine[start])))
+		for _, line := range lines[start:end] {
+			result.WriteByte(line.op)
+			result.WriteString(line.text)
+			if !strings.HasSuffix(line.text, "\n") {
+				result.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		k = end
+	}
+	return result.String()
+}
+
+// hunkRange formats the start and length of a hunk, where start counts
+// the lines before it
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// splitLines splits text into lines that keep their line break
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns an edit script from a to b with the fewest removed and
+// added lines, found with the longest common subsequence of the lines
+// between their common prefix and suffix
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var result []diffLine
+	for _, line := range a[:prefix] {
+		result = append(result, diffLine{' ', line})
+	}
+	result = append(result, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		result = append(result, diffLine{' ', line})
+	}
+	return result
+}
+
+// diffMiddle returns an edit script for lines that differ at both ends
+func diffMiddle(a, b []string) []diffLine {
+	var result []diffLine
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			result = append(result, diffLine{'-', line})
+		}
+		for _, line := range b {
+			result = append(result, diffLine{'+', line})
+		}
+		return result
+	}
+
+	// lengths[i][j] is the length of the common subsequence of a[i:] and b[j:]
+	lengths := make([][]int32, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, diffLine{' ', a[i]})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			result = append(result, diffLine{'-', a[i]})
+			i++
+		default:
+			result = append(result, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		result = append(result, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		result = append(result, diffLine{'+', b[j]})
+	}
+	return result
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "same",
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: "",
+		},
+		{
+			name: "changed line",
+			old:  "a\nb\nc\n",
+			new:  "a\nB\nc\n",
+			expected: "--- old.md\n+++ new.md\n" +
+				"@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "added to empty file",
+			old:  "",
+			new:  "a\n",
+			expected: "--- old.md\n+++ new.md\n" +
+				"@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name: "no newline at end",
+			old:  "a\nb",
+			new:  "a\nb\n",
+			expected: "--- old.md\n+++ new.md\n" +
+				"@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		result := unifiedDiff("old.md", "new.md", tt.old, tt.new)
+		if result != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, result)
+		}
+	}
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d\n", i))
+	}
+	old := strings.Join(lines, "")
+	lines[1] = "changed 2\n"
+	lines[17] = "changed 18\n"
+	current := strings.Join(lines, "")
+
+	result := unifiedDiff("old.md", "new.md", old, current)
+
+	// Changes more than twice the context apart are shown in separate hunks
+	if !strings.Contains(result, "@@ -1,5 +1,5 @@\n") || !strings.Contains(result, "@@ -15,6 +15,6 @@\n") {
+		t.Errorf("Expected two hunks, got %q", result)
+	}
+	if strings.Contains(result, "line 10\n") {
+		t.Errorf("Expected unchanged lines between hunks to be omitted, got %q", result)
+	}
+}
+
+func TestStripFrontMatterTimestamps(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "---\ntitle: \"Page\"\ncreated: \"2024-01-01T00:00:00Z\"\nupdated: 2024-01-02T10:30:00+09:00\ndate: 2024-01-01\n---\n\nupdated: 2024-01-02T10:30:00Z\n",
+			expected: "---\ntitle: \"Page\"\ndate: 2024-01-01\n---\n\nupdated: 2024-01-02T10:30:00Z\n",
+		},
+		{
+			input:    "+++\ntitle = \"Page\"\nlastmod = 2024-01-02T10:30:00Z\n+++\n",
+			expected: "+++\ntitle = \"Page\"\n+++\n",
+		},
+		{
+			input:    "# No front matter\ncreated: 2024-01-01T00:00:00Z\n",
+			expected: "# No front matter\ncreated: 2024-01-01T00:00:00Z\n",
+		},
+		{
+			// A leading thematic break without a closing delimiter
+			input:    "---\n\ncreated: 2024-01-01T00:00:00Z\n",
+			expected: "---\n\ncreated: 2024-01-01T00:00:00Z\n",
+		},
+	}
+
+	for _, tt := range tests {
+		result := stripFrontMatterTimestamps(tt.input)
+		if result != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, result)
+		}
+	}
+}
+
+func TestStripTimestamps(t *testing.T) {
+	info := notiontomd.PageInfo{
+		Title:          "Page",
+		URL:            "https://www.notion.so/Page",
+		CreatedTime:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		LastEditedTime: time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC),
+	}
+	edited := info
+	edited.LastEditedTime = edited.LastEditedTime.Add(time.Hour)
+	body := "Body\n"
+
+	tests := []struct {
+		name   string
+		format notiontomd.Format
+		header func(info notiontomd.PageInfo) string
+	}{
+		{"org", notiontomd.FormatOrg, notiontomd.OrgHeader},
+		{"asciidoc", notiontomd.FormatAsciiDoc, notiontomd.AsciiDocHeader},
+		{"html", notiontomd.FormatHTML, func(info notiontomd.PageInfo) string {
+			return notiontomd.HTMLDocument(info, body, false)
+		}},
+		{"markdown", notiontomd.FormatMarkdown, notiontomd.GenerateFrontMatter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := stripTimestamps(tt.header(info)+body, tt.format)
+			current := stripTimestamps(tt.header(edited)+body, tt.format)
+			if old != current {
+				t.Errorf("Expected the timestamps to be ignored, got %q and %q", old, current)
+			}
+			if !strings.Contains(current, "Page") {
+				t.Errorf("Expected the title to be kept, got %q", current)
+			}
+		})
+	}
+}
+
+func TestStripTimestampsKeepsBody(t *testing.T) {
+	content := "#+TITLE: Page\n#+UPDATED: [2024-01-02 Tue 10:30]\n\n#+UPDATED: [2024-01-02 Tue 10:30]\n"
+
+	expected := "#+TITLE: Page\n\n#+UPDATED: [2024-01-02 Tue 10:30]\n"
+	if result := stripTimestamps(content, notiontomd.FormatOrg); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestCheckIgnoreTimestamps(t *testing.T) {
+	tests := []struct {
+		name     string
+		render   renderOptions
+		expected bool
+	}{
+		{"markdown", renderOptions{format: "markdown"}, true},
+		{"org", renderOptions{format: "org"}, true},
+		{"json", renderOptions{format: "json"}, false},
+		{"hatena-atompub", renderOptions{format: "markdown", preset: "hatena-atompub"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkIgnoreTimestamps(&tt.render); (err == nil) != tt.expected {
+				t.Errorf("Expected supported %t, got error %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+var epubCommand = &command{
+	name:    "epub",
+	usage:   "epub [flags] <page-id-or-url>",
+	summary: "Convert a page into an EPUB book",
+	description: "Fetch a page and write it as an EPUB 3 book to the file given with -o, or to a file named\n" +
+		"after the page. With --children, its child pages are added recursively as further chapters.\n" +
+		"Images are downloaded into the book, and the table of contents lists the headings of every page.",
+	examples: []string{
+		"notion-to-md epub cec15681-9083-4e1f-a0ae-72d268507aab",
+		"notion-to-md epub --children --language ja -o handbook.epub cec15681-9083-4e1f-a0ae-72d268507aab",
+	},
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var tokens tokenOptions
+		var cacheOpts cacheOptions
+		var outFile string
+		var children bool
+		var language string
+		var depth int
+		var truncate bool
+		var slugName string
+		tokens.register(fs)
+		cacheOpts.register(fs)
+		fs.StringVar(&outFile, "o", "", "write the book to `FILE` (default: the page name with .epub)")
+		fs.BoolVar(&children, "children", false, "add the child pages recursively as chapters")
+		fs.StringVar(&language, "language", "", "BCP 47 `language` of the book (default: und)")
+		fs.IntVar(&depth, "depth", notiontomd.DefaultMaxDepth, "maximum nesting depth to fetch (0 for unlimited)")
+		fs.BoolVar(&truncate, "truncate", false, "omit blocks nested deeper than --depth instead of failing")
+		fs.StringVar(&slugName, "slug", string(slugTitle), "file name `strategy` when -o is not given: title, ascii, title-id or id")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return errUsage
+			}
+			if depth < 0 {
+				return fmt.Errorf("--depth must not be negative")
+			}
+			slug, err := parseSlugStrategy(slugName)
+			if err != nil {
+				return err
+			}
+
+			client, err := tokens.newClient()
+			if err != nil {
+				return err
+			}
+			cache, err := cacheOpts.open()
+			if err != nil {
+				return err
+			}
+
+			conv := notiontomd.New(client, notiontomd.Options{
+				Format:   notiontomd.FormatHTML,
+				MaxDepth: depth,
+				Truncate: truncate,
+				OnTruncate: func(blockID notionapi.BlockID, _ int) {
+					fmt.Fprintf(os.Stderr, "Warning: block %s has children nested deeper than %d levels; truncated\n", blockID, depth)
+				},
+				OmitFrontMatter: true,
+				Cache:           cache,
+			})
+			root, err := conv.ConvertPage(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			docs := []notiontomd.Document{root}
+			if children {
+				if docs, err = appendChildPages(ctx, conv, docs, root); err != nil {
+					return err
+				}
+			}
+
+			var buf bytes.Buffer
+			err = notiontomd.WriteEPUB(ctx, &buf, docs, notiontomd.EPUBOptions{
+				Language: language,
+				Download: func(ctx context.Context, url string) ([]byte, error) {
+					return fetchURL(ctx, http.DefaultClient, url)
+				},
+			})
+			if err != nil {
+				return err
+			}
+			if outFile == "" {
+				outFile = pageSlug(root.Info, slug) + ".epub"
+			}
+			return writeOutput(outFile, buf.String())
+		}
+	},
+}
+
+// appendChildPages converts the child pages of doc depth-first and appends
+// them to docs, in the order they appear on the page
+func appendChildPages(ctx context.Context, conv *notiontomd.Converter, docs []notiontomd.Document, doc notiontomd.Document) ([]notiontomd.Document, error) {
+	for _, id := range childPageIDs(doc.Blocks) {
+		child, err := conv.ConvertPage(ctx, string(id))
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, child)
+		if docs, err = appendChildPages(ctx, conv, docs, child); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+// childPageIDs returns the IDs of the child pages referenced by the blocks
+func childPageIDs(blocks []notiontomd.BlockWithIndent) []notionapi.BlockID {
+	var ids []notionapi.BlockID
+	for _, bwi := range blocks {
+		if bwi.Block.GetType() == notionapi.BlockTypeChildPage {
+			ids = append(ids, bwi.Block.GetID())
+		}
+	}
+	return ids
+}
+
+// childPages returns the IDs and titles of the child pages referenced by the blocks
+func childPages(blocks []notiontomd.BlockWithIndent) []notiontomd.PageInfo {
+	var pages []notiontomd.PageInfo
+	for _, bwi := range blocks {
+		if block, ok := bwi.Block.(*notionapi.ChildPageBlock); ok {
+			pages = append(pages, notiontomd.PageInfo{ID: block.ID, Title: block.ChildPage.Title})
+		}
+	}
+	return pages
+}
+
+// childDir returns the directory the child pages of the page written to path are exported into
+func childDir(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
+
+// queryDatabasePages returns the metadata of every page in a database
+func queryDatabasePages(ctx context.Context, client *notionapi.Client, databaseID notionapi.DatabaseID) ([]notiontomd.PageInfo, error) {
+	var pages []notiontomd.PageInfo
+	request := &notionapi.DatabaseQueryRequest{}
+
+	for {
+		resp, err := client.Database.Query(ctx, databaseID, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query database %s: %w", databaseID, err)
+		}
+
+		for i := range resp.Results {
+			pages = append(pages, notiontomd.PageInfoFromPage(&resp.Results[i]))
+		}
+
+		if !resp.HasMore {
+			break
+		}
+		request.StartCursor = resp.NextCursor
+	}
+
+	return pages, nil
+}
+
+// exportResult describes a page handled by pageExporter
+type exportResult struct {
+	Info     notiontomd.PageInfo
+	Path     string
+	Children []notionapi.BlockID
+	// Skipped is set when a sync found the page unchanged and did not rewrite it
+	Skipped bool
+}
+
+// pageNames assigns the output paths of exported pages. Pages whose titles
+// yield the same file name in a directory get their ID appended, except for
+// the page that claimed the name first.
+type pageNames struct {
+	slug      slugStrategy
+	extension string
+
+	mu sync.Mutex
+	// owners maps claimed paths to the page written there
+	owners map[string]notionapi.BlockID
+	// paths maps pages to the path they claimed last
+	paths map[notionapi.BlockID]string
+}
+
+// newPageNames creates the names of pages exported with extension
+func newPageNames(slug slugStrategy, extension string) *pageNames {
+	return &pageNames{
+		slug:      slug,
+		extension: extension,
+		owners:    make(map[string]notionapi.BlockID),
+		paths:     make(map[notionapi.BlockID]string),
+	}
+}
+
+// titlePath returns the path the page gets in dir when its name is not taken
+func (n *pageNames) titlePath(dir string, info notiontomd.PageInfo) string {
+	return filepath.Join(dir, pageSlug(info, n.slug)+n.extension)
+}
+
+// claim returns the output path of the page in dir. A page keeps the path
+// it claimed before, so exporting it again writes the same file.
+func (n *pageNames) claim(dir string, info notiontomd.PageInfo) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	path := n.titlePath(dir, info)
+	if owner, ok := n.owners[path]; ok && owner != info.ID {
+		path = filepath.Join(dir, pageSlug(info, slugTitleID)+n.extension)
+	}
+	if previous, ok := n.paths[info.ID]; ok && previous != path {
+		delete(n.owners, previous)
+	}
+	n.owners[path] = info.ID
+	n.paths[info.ID] = path
+	return path
+}
+
+// linkTarget returns the file name without extension that links to a page
+// point to: the name the page claimed, or the name derived from its title
+// when it was not claimed, e.g. because it is not exported.
+func (n *pageNames) linkTarget(pageID, title string) string {
+	n.mu.Lock()
+	path, ok := n.paths[notionapi.BlockID(pageID)]
+	n.mu.Unlock()
+	if !ok {
+		return pageSlug(notiontomd.PageInfo{ID: notionapi.BlockID(pageID), Title: title}, n.slug)
+	}
+	return strings.TrimSuffix(filepath.Base(path), n.extension)
+}
+
+// pageExporter exports pages into a directory with bounded concurrency
+type pageExporter struct {
+	conv      *notiontomd.Converter
+	names     *pageNames
+	recursive bool
+	// state is set during a sync to skip pages that did not change
+	state *syncState
+	// attachments is the directory embedded files are downloaded into, if any
+	attachments string
+
+	sem  chan struct{}
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// newPageExporter creates an exporter fetching at most concurrency pages at a time.
+// When recursive is set, child pages are exported into a directory named after their parent.
+func newPageExporter(conv *notiontomd.Converter, names *pageNames, concurrency int, recursive bool) *pageExporter {
+	return &pageExporter{
+		conv:      conv,
+		names:     names,
+		recursive: recursive,
+		sem:       make(chan struct{}, concurrency),
+	}
+}
+
+// add schedules pages for export into dir. Pages are given by ID, or by
+// their metadata when it is already known, e.g. from a database query.
+// The metadata of every page is fetched before any of them is written, so
+// that pages with the same title are named the same way in every run.
+func (e *pageExporter) add(ctx context.Context, ids []notionapi.BlockID, infos []notiontomd.PageInfo, dir string) {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		fetched := make([]*notiontomd.PageInfo, len(ids))
+		var wg sync.WaitGroup
+		for i, id := range ids {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				info, err := e.pageInfo(ctx, id)
+				if err != nil {
+					e.fail(err)
+					return
+				}
+				fetched[i] = &info
+			}()
+		}
+		wg.Wait()
+
+		pages := append([]notiontomd.PageInfo(nil), infos...)
+		for _, info := range fetched {
+			if info != nil {
+				pages = append(pages, *info)
+			}
+		}
+
+		paths := e.claimPaths(dir, pages)
+		for i, info := range pages {
+			e.wg.Add(1)
+			go func() {
+				defer e.wg.Done()
+				result, err := e.export(ctx, info, paths[i])
+				if err != nil {
+					e.fail(err)
+					return
+				}
+				if e.recursive && len(result.Children) > 0 {
+					e.add(ctx, result.Children, nil, childDir(result.Path))
+				}
+			}()
+		}
+	}()
+}
+
+// fail records an error returned by wait
+func (e *pageExporter) fail(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errs = append(e.errs, err)
+}
+
+// pageInfo fetches the metadata of a page once a slot is free
+func (e *pageExporter) pageInfo(ctx context.Context, pageID notionapi.BlockID) (notiontomd.PageInfo, error) {
+	e.sem <- struct{}{}
+	defer func() { <-e.sem }()
+
+	info, err := e.conv.PageInfo(ctx, string(pageID))
+	if err != nil {
+		return notiontomd.PageInfo{}, fmt.Errorf("page %s: %w", pageID, err)
+	}
+	return info, nil
+}
+
+// claimPaths returns the output paths of pages exported together into dir.
+// When titles collide, a page that the previous sync wrote to the title
+// path keeps it; otherwise the page with the lowest ID gets it.
+func (e *pageExporter) claimPaths(dir string, infos []notiontomd.PageInfo) []string {
+	order := make([]int, len(infos))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ia, ib := infos[order[a]], infos[order[b]]
+		if ka, kb := e.keepsTitlePath(dir, ia), e.keepsTitlePath(dir, ib); ka != kb {
+			return ka
+		}
+		return ia.ID < ib.ID
+	})
+
+	paths := make([]string, len(infos))
+	for _, i := range order {
+		paths[i] = e.names.claim(dir, infos[i])
+	}
+	return paths
+}
+
+// keepsTitlePath reports whether the previous sync wrote the page to the
+// path derived from its title alone
+func (e *pageExporter) keepsTitlePath(dir string, info notiontomd.PageInfo) bool {
+	if e.state == nil {
+		return false
+	}
+	entry, ok := e.state.previous.Pages[info.ID]
+	return ok && entry.Path == e.state.relPath(e.names.titlePath(dir, info))
+}
+
+// export fetches, renders and writes a single page to path once a slot is
+// free. During a sync, pages unchanged since the last run are not fetched again.
+func (e *pageExporter) export(ctx context.Context, info notiontomd.PageInfo, path string) (*exportResult, error) {
+	e.sem <- struct{}{}
+	defer func() { <-e.sem }()
+
+	if e.state != nil {
+		if entry, ok := e.state.skip(info, path); ok {
+			return &exportResult{Info: info, Path: path, Children: entry.Children, Skipped: true}, nil
+		}
+	}
+
+	blocks, err := e.conv.FetchBlocks(ctx, info)
+	if err != nil {
+		return nil, fmt.Errorf("page %s: %w", info.ID, err)
+	}
+	if e.recursive {
+		// Child pages claim their paths before the page is rendered, so that
+		// links to them point to the files they are written to
+		e.claimPaths(childDir(path), childPages(blocks))
+	}
+	doc, err := e.conv.ConvertBlocks(ctx, info, blocks)
+	if err != nil {
+		return nil, fmt.Errorf("page %s: %w", info.ID, err)
+	}
+	content := doc.Content
+	if err := writeOutput(path, content); err != nil {
+		return nil, fmt.Errorf("page %s: %w", info.ID, err)
+	}
+	if e.attachments != "" {
+		if err := downloadAttachments(ctx, http.DefaultClient, notiontomd.Attachments(doc.Nodes), e.attachments); err != nil {
+			return nil, fmt.Errorf("page %s: %w", info.ID, err)
+		}
+	}
+
+	children := childPageIDs(doc.Blocks)
+	if e.state != nil {
+		e.state.record(info, path, content, children)
+	}
+	return &exportResult{Info: info, Path: path, Children: children}, nil
+}
+
+// wait blocks until every scheduled page is exported and returns the collected errors
+func (e *pageExporter) wait() error {
+	e.wg.Wait()
+	return errors.Join(e.errs...)
+}
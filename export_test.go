@@ -0,0 +1,121 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+func TestClaimPathsOrdersCollisionsByID(t *testing.T) {
+	pages := []notiontomd.PageInfo{
+		{ID: "bbbb", Title: "Meeting notes"},
+		{ID: "aaaa", Title: "Meeting notes"},
+		{ID: "cccc", Title: "Other"},
+	}
+
+	// The order the pages arrive in does not matter
+	for _, order := range [][]int{{0, 1, 2}, {1, 2, 0}} {
+		exporter := newPageExporter(nil, newPageNames(slugTitle, ".md"), 1, false)
+		var infos []notiontomd.PageInfo
+		for _, i := range order {
+			infos = append(infos, pages[i])
+		}
+
+		paths := make(map[notionapi.BlockID]string)
+		for i, path := range exporter.claimPaths("docs", infos) {
+			paths[infos[i].ID] = path
+		}
+
+		expected := map[notionapi.BlockID]string{
+			"aaaa": filepath.Join("docs", "meeting-notes.md"),
+			"bbbb": filepath.Join("docs", "meeting-notes-bbbb.md"),
+			"cccc": filepath.Join("docs", "other.md"),
+		}
+		if !reflect.DeepEqual(paths, expected) {
+			t.Errorf("Expected %v, got %v", expected, paths)
+		}
+	}
+}
+
+func TestClaimPathsKeepsPreviousPath(t *testing.T) {
+	dir := t.TempDir()
+	previous := newManifest("opts")
+	previous.Pages["bbbb"] = manifestEntry{Path: "meeting-notes.md"}
+	previous.Pages["aaaa"] = manifestEntry{Path: "meeting-notes-aaaa.md"}
+
+	exporter := newPageExporter(nil, newPageNames(slugTitle, ".md"), 1, false)
+	exporter.state = newSyncState(dir, previous, "opts")
+	paths := exporter.claimPaths(dir, []notiontomd.PageInfo{
+		{ID: "aaaa", Title: "Meeting notes"},
+		{ID: "bbbb", Title: "Meeting notes"},
+	})
+
+	expected := []string{filepath.Join(dir, "meeting-notes-aaaa.md"), filepath.Join(dir, "meeting-notes.md")}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+}
+
+func TestPageNamesClaimAgain(t *testing.T) {
+	names := newPageNames(slugTitle, ".md")
+	first := names.claim("docs", notiontomd.PageInfo{ID: "aaaa", Title: "Page"})
+
+	// Exporting a page again writes the same file
+	if path := names.claim("docs", notiontomd.PageInfo{ID: "aaaa", Title: "Page"}); path != first {
+		t.Errorf("Expected %q, got %q", first, path)
+	}
+
+	// A renamed page releases its old name
+	names.claim("docs", notiontomd.PageInfo{ID: "aaaa", Title: "Renamed"})
+	if path := names.claim("docs", notiontomd.PageInfo{ID: "bbbb", Title: "Page"}); path != first {
+		t.Errorf("Expected %q, got %q", first, path)
+	}
+}
+
+func TestPageNamesLinkTarget(t *testing.T) {
+	names := newPageNames(slugTitle, ".md")
+	exporter := newPageExporter(nil, names, 1, false)
+	exporter.claimPaths("docs", []notiontomd.PageInfo{
+		{ID: "bbbb", Title: "Meeting notes"},
+		{ID: "aaaa", Title: "Meeting notes"},
+	})
+
+	tests := []struct {
+		name     string
+		pageID   string
+		title    string
+		expected string
+	}{
+		{"Claimed title path", "aaaa", "Meeting notes", "meeting-notes"},
+		{"Claimed after a collision", "bbbb", "Meeting notes", "meeting-notes-bbbb"},
+		{"Not exported", "cccc", "Other", "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if target := names.linkTarget(tt.pageID, tt.title); target != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, target)
+			}
+		})
+	}
+}
+
+func TestChildPages(t *testing.T) {
+	blocks := []notiontomd.BlockWithIndent{
+		{Block: &notionapi.ParagraphBlock{BasicBlock: notionapi.BasicBlock{ID: "para", Type: notionapi.BlockTypeParagraph}}},
+		{Block: &notionapi.ChildPageBlock{
+			BasicBlock: notionapi.BasicBlock{ID: "child", Type: notionapi.BlockTypeChildPage},
+			ChildPage: struct {
+				Title string `json:"title"`
+			}{Title: "Child"},
+		}},
+	}
+
+	expected := []notiontomd.PageInfo{{ID: "child", Title: "Child"}}
+	if pages := childPages(blocks); !reflect.DeepEqual(pages, expected) {
+		t.Errorf("Expected %v, got %v", expected, pages)
+	}
+}
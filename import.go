@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+var importCommand = &command{
+	name:    "import",
+	usage:   "import [flags] <page-id-or-url> [FILE]",
+	summary: "Write Markdown into a page",
+	description: "Convert Markdown from FILE, or stdin when FILE is omitted or \"-\", into Notion blocks and append\n" +
+		"them to the page. With --replace, the existing content of the page is deleted once the new\n" +
+		"blocks are written; child pages and databases are kept and end up above the new content.\n" +
+		"When a write fails, the blocks already written are removed again. With --dry-run, the planned\n" +
+		"blocks are printed as JSON and nothing is sent to Notion.",
+	examples: []string{
+		"notion-to-md import cec15681-9083-4e1f-a0ae-72d268507aab notes.md",
+		"notion-to-md import --replace cec15681-9083-4e1f-a0ae-72d268507aab < notes.md",
+		"notion-to-md import --dry-run cec15681-9083-4e1f-a0ae-72d268507aab notes.md",
+	},
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var tokens tokenOptions
+		var replace bool
+		var dryRun bool
+		tokens.register(fs)
+		fs.BoolVar(&replace, "replace", false, "delete the existing blocks of the page, except child pages and databases, after writing")
+		fs.BoolVar(&dryRun, "dry-run", false, "print the blocks as JSON instead of writing them")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) < 1 || len(args) > 2 {
+				return errUsage
+			}
+			pageID, err := notiontomd.ExtractBlockID(args[0])
+			if err != nil {
+				return err
+			}
+
+			var source []byte
+			if len(args) == 1 || args[1] == "-" {
+				source, err = io.ReadAll(os.Stdin)
+			} else {
+				source, err = os.ReadFile(args[1])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read Markdown: %w", err)
+			}
+			blocks := notiontomd.ParseMarkdown(string(source))
+
+			if dryRun {
+				data, err := json.MarshalIndent(blocks, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode blocks: %w", err)
+				}
+				return writeOutput("", string(data)+"\n")
+			}
+
+			client, err := tokens.newClient()
+			if err != nil {
+				return err
+			}
+			if err := notiontomd.WriteBlocks(ctx, client.Block, pageID, blocks, notiontomd.WriteOptions{Replace: replace}); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Wrote %d blocks to %s\n", len(blocks), pageID)
+			return nil
+		}
+	},
+}
@@ -1,61 +1,7 @@
 package main
 
-import (
-	"context"
-	"fmt"
-	"os"
-
-	"github.com/jomei/notionapi"
-)
+import "os"
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "Usage: notion-to-md <block-id-or-url>")
-		fmt.Fprintln(os.Stderr, "")
-		fmt.Fprintln(os.Stderr, "Examples:")
-		fmt.Fprintln(os.Stderr, "  notion-to-md cec15681-9083-4e1f-a0ae-72d268507aab")
-		fmt.Fprintln(os.Stderr, "  notion-to-md https://www.notion.so/10xall/By-name-cec1568190834e1fa0ae72d268507aab")
-		os.Exit(1)
-	}
-	input := os.Args[1]
-
-	// Extract block ID from URL or use directly
-	blockID, err := extractBlockID(input)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Get NOTION_TOKEN
-	token := os.Getenv("NOTION_TOKEN")
-	if token == "" {
-		fmt.Fprintln(os.Stderr, "Error: NOTION_TOKEN environment variable not set")
-		os.Exit(1)
-	}
-
-	// Initialize Notion client
-	client := notionapi.NewClient(notionapi.Token(token))
-
-	ctx := context.Background()
-
-	// Fetch page information
-	pageInfo, err := fetchPageInfo(ctx, client, notionapi.PageID(blockID))
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error fetching page info: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Fetch all blocks recursively
-	blocks, err := fetchAllBlocks(ctx, client.Block, blockID)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error fetching blocks: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Generate front-matter and convert to Markdown
-	frontMatter := generateFrontMatter(pageInfo)
-	markdown := convert(blocks)
-
-	fmt.Print(frontMatter)
-	fmt.Print(markdown)
+	os.Exit(run(os.Args[1:]))
 }
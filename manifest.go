@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// manifestFile is the name of the sync manifest inside the output directory
+const manifestFile = ".notion-to-md.json"
+
+// manifest records what the last sync wrote, keyed by page ID
+type manifest struct {
+	// Options fingerprints the flags that affect the output. Pages are only
+	// skipped when the options did not change since the last sync.
+	Options string                              `json:"options"`
+	Pages   map[notionapi.BlockID]manifestEntry `json:"pages"`
+}
+
+// manifestEntry records a single exported page
+type manifestEntry struct {
+	Title          string    `json:"title"`
+	LastEditedTime time.Time `json:"last_edited_time"`
+	// Path is relative to the output directory and uses forward slashes
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+	// Children lists the child pages, so that the tree below an unchanged
+	// page can be synced without fetching its blocks
+	Children []notionapi.BlockID `json:"children,omitempty"`
+}
+
+// newManifest creates an empty manifest for the given options fingerprint
+func newManifest(options string) *manifest {
+	return &manifest{
+		Options: options,
+		Pages:   make(map[notionapi.BlockID]manifestEntry),
+	}
+}
+
+// loadManifest reads the manifest from dir. A missing manifest yields an empty one.
+func loadManifest(dir string) (*manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return newManifest(""), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	m := newManifest("")
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", filepath.Join(dir, manifestFile), err)
+	}
+	if m.Pages == nil {
+		m.Pages = make(map[notionapi.BlockID]manifestEntry)
+	}
+	return m, nil
+}
+
+// save writes the manifest into dir
+func (m *manifest) save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return writeOutput(filepath.Join(dir, manifestFile), string(data)+"\n")
+}
+
+// contentHash returns the hash recorded for exported content
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,143 @@
+package notiontomd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// AsciiDocHeader generates an AsciiDoc document header from page metadata
+func AsciiDocHeader(info PageInfo) string {
+	var result strings.Builder
+
+	result.WriteString("= " + info.Title + "\n")
+	result.WriteString(":notion-url: " + info.URL + "\n")
+	result.WriteString(":created: " + info.CreatedTime.Format(time.RFC3339) + "\n")
+	result.WriteString(":revdate: " + info.LastEditedTime.Format(time.RFC3339) + "\n")
+	result.WriteString("\n")
+
+	return result.String()
+}
+
+// ConvertAsciiDoc converts blocks with indentation to AsciiDoc
+func ConvertAsciiDoc(blocks []BlockWithIndent) string {
+	return RenderAsciiDoc(NewNodes(blocks))
+}
+
+// RenderAsciiDoc converts a block tree to AsciiDoc
+func RenderAsciiDoc(nodes []*Node) string {
+	var result strings.Builder
+	writeAsciiDocNodes(&result, nodes, 0)
+	return result.String()
+}
+
+// writeAsciiDocNodes writes sibling blocks. depth is the list nesting level.
+func writeAsciiDocNodes(w *strings.Builder, nodes []*Node, depth int) {
+	for i, node := range nodes {
+		writeAsciiDocNode(w, node, depth)
+
+		// A blank line ends a top-level list before the next block
+		if depth == 0 && isListItem(node.Type) && (i == len(nodes)-1 || !isListItem(nodes[i+1].Type)) {
+			w.WriteString("\n")
+		}
+	}
+}
+
+// writeAsciiDocChildren writes the nested blocks of a node and the truncation marker
+func writeAsciiDocChildren(w *strings.Builder, node *Node, depth int) {
+	writeAsciiDocNodes(w, node.Children, depth)
+	if node.Truncated {
+		w.WriteString(strings.Repeat("*", depth+1) + " _(truncated: nested blocks omitted)_\n")
+	}
+}
+
+// writeAsciiDocNode writes a single block with its nested blocks
+func writeAsciiDocNode(w *strings.Builder, node *Node, depth int) {
+	switch node.Type {
+	case notionapi.BlockTypeHeading1, notionapi.BlockTypeHeading2, notionapi.BlockTypeHeading3:
+		// A single = is the document title, so Notion's headings start at level 1
+		marker := strings.Repeat("=", headingLevel(node.Type)+1)
+		w.WriteString(marker + " " + joinLines(FormatRichTextAsciiDoc(node.Text), " ") + "\n\n")
+		writeAsciiDocChildren(w, node, depth)
+	case notionapi.BlockTypeParagraph:
+		if text := FormatRichTextAsciiDoc(node.Text); text != "" {
+			w.WriteString(joinLines(text, " +\n") + "\n\n")
+		}
+		writeAsciiDocChildren(w, node, depth)
+	case notionapi.BlockTypeBulletedListItem:
+		marker := strings.Repeat("*", depth+1)
+		w.WriteString(marker + " " + joinLines(FormatRichTextAsciiDoc(node.Text), " +\n") + "\n")
+		writeAsciiDocChildren(w, node, depth+1)
+	case notionapi.BlockTypeNumberedListItem:
+		marker := strings.Repeat(".", depth+1)
+		w.WriteString(marker + " " + joinLines(FormatRichTextAsciiDoc(node.Text), " +\n") + "\n")
+		writeAsciiDocChildren(w, node, depth+1)
+	case notionapi.BlockTypeToDo:
+		checkbox := "[ ] "
+		if node.Checked {
+			checkbox = "[x] "
+		}
+		marker := strings.Repeat("*", depth+1)
+		w.WriteString(marker + " " + checkbox + joinLines(FormatRichTextAsciiDoc(node.Text), " +\n") + "\n")
+		writeAsciiDocChildren(w, node, depth+1)
+	case notionapi.BlockTypeToggle:
+		w.WriteString("." + joinLines(FormatRichTextAsciiDoc(node.Text), " ") + "\n")
+		w.WriteString("[%collapsible]\n====\n")
+		writeAsciiDocChildren(w, node, 0)
+		w.WriteString("====\n\n")
+	case notionapi.BlockTypeCode:
+		if lang := sourceLanguage(node.Language); lang != "" {
+			w.WriteString("[source," + lang + "]\n")
+		} else {
+			w.WriteString("[source]\n")
+		}
+		w.WriteString("----\n" + PlainText(node.Text) + "\n----\n\n")
+	case notionapi.BlockTypeQuote:
+		w.WriteString("[quote]\n____\n" + joinLines(FormatRichTextAsciiDoc(node.Text), " +\n") + "\n")
+		writeAsciiDocChildren(w, node, 0)
+		w.WriteString("____\n\n")
+	case notionapi.BlockTypeCallout:
+		w.WriteString("[NOTE]\n====\n" + calloutPrefix(node) + joinLines(FormatRichTextAsciiDoc(node.Text), " +\n") + "\n")
+		writeAsciiDocChildren(w, node, 0)
+		w.WriteString("====\n\n")
+	case notionapi.BlockTypeDivider:
+		w.WriteString("'''\n\n")
+	default:
+		writeAsciiDocChildren(w, node, depth)
+	}
+}
+
+// FormatRichTextAsciiDoc converts rich text to AsciiDoc markup. Unconstrained
+// marks are used so that formatting also applies inside words.
+func FormatRichTextAsciiDoc(spans []Span) string {
+	var result strings.Builder
+
+	for _, span := range spans {
+		text := span.Text
+
+		if span.Code {
+			text = "``" + text + "``"
+		}
+		if span.Bold {
+			text = "**" + text + "**"
+		}
+		if span.Italic {
+			text = "__" + text + "__"
+		}
+		if span.Strikethrough {
+			text = "[.line-through]##" + text + "##"
+		}
+		if span.Underline {
+			text = "[.underline]##" + text + "##"
+		}
+
+		if span.Href != "" {
+			text = "link:" + span.Href + "[" + strings.ReplaceAll(text, "]", "\\]") + "]"
+		}
+
+		result.WriteString(text)
+	}
+
+	return result.String()
+}
@@ -0,0 +1,80 @@
+package notiontomd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestConvertAsciiDoc(t *testing.T) {
+	result := ConvertAsciiDoc(sampleBlocks())
+	expected := "== Title\n\n" +
+		"Some **bold** and ``code`` with a link:https://example.com[link]\n\n" +
+		"* Parent\n" +
+		".. Child\n" +
+		"* Sibling\n\n" +
+		"[source,go]\n----\nfmt.Println(1)\n----\n\n" +
+		"[quote]\n____\nQuoted\n____\n\n" +
+		"[NOTE]\n====\nNote\n====\n\n" +
+		"'''\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertAsciiDocToggle(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{
+			Block: &notionapi.ToggleBlock{
+				BasicBlock: notionapi.BasicBlock{Object: "block", Type: notionapi.BlockTypeToggle, HasChildren: true},
+				Toggle: notionapi.Toggle{
+					RichText: []notionapi.RichText{{PlainText: "More"}},
+				},
+			},
+			Indent: 0,
+		},
+		{Block: createBulletedListBlock("block-1", "Hidden", false), Indent: 1},
+	}
+
+	result := ConvertAsciiDoc(blocks)
+	expected := ".More\n[%collapsible]\n====\n* Hidden\n\n====\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestFormatRichTextAsciiDoc(t *testing.T) {
+	spans := []Span{
+		{Type: "text", Text: "gone", Strikethrough: true},
+		{Type: "text", Text: "[a]", Href: "https://example.com"},
+	}
+
+	result := FormatRichTextAsciiDoc(spans)
+	expected := "[.line-through]##gone##link:https://example.com[[a\\]]"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestAsciiDocHeader(t *testing.T) {
+	info := PageInfo{
+		Title:          "Page",
+		URL:            "https://www.notion.so/page",
+		CreatedTime:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		LastEditedTime: time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC),
+	}
+
+	result := AsciiDocHeader(info)
+	expected := "= Page\n" +
+		":notion-url: https://www.notion.so/page\n" +
+		":created: 2024-01-01T12:00:00Z\n" +
+		":revdate: 2024-01-02T15:30:00Z\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,154 @@
+package notiontomd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// maxBlogTags is the number of tags Zenn and Qiita accept per article
+const maxBlogTags = 5
+
+// defaultZennEmoji is used for pages without an emoji icon
+const defaultZennEmoji = "📝"
+
+// Zenn writes the front matter of the Zenn CLI, :::message blocks for
+// callouts, :::details blocks for toggles and link cards for bookmarks
+var Zenn = &Preset{
+	Name: "zenn",
+	FrontMatter: func(meta SiteMeta) string {
+		emoji := meta.Info.Icon
+		if emoji == "" || strings.Contains(emoji, "://") {
+			emoji = defaultZennEmoji
+		}
+
+		var result strings.Builder
+		result.WriteString("---\n")
+		result.WriteString("title: " + quoteString(meta.Title) + "\n")
+		result.WriteString("emoji: " + quoteString(emoji) + "\n")
+		result.WriteString("type: \"tech\"\n")
+		result.WriteString("topics: " + quoteList(blogTags(meta.Tags)) + "\n")
+		fmt.Fprintf(&result, "published: %t\n", !meta.Draft)
+		result.WriteString("---\n\n")
+		return result.String()
+	},
+	Register: func(r *Renderer) {
+		r.Register(notionapi.BlockTypeCallout, func(r *Renderer, node *Node, depth int) string {
+			kind := "message"
+			if calloutLevel(node) != "info" {
+				kind = "message alert"
+			}
+			return renderColonBlock(r, node, kind, r.RichText(node.Text))
+		})
+		r.Register(notionapi.BlockTypeToggle, func(r *Renderer, node *Node, depth int) string {
+			fence := colonFence(node)
+			title := joinLines(r.RichText(node.Text), " ")
+			return fence + "details " + title + "\n" + blankLineAfter(r.Children(node, 0)) + fence + "\n\n"
+		})
+		r.Register(notionapi.BlockTypeBookmark, func(r *Renderer, node *Node, depth int) string {
+			if node.URL == "" {
+				return ""
+			}
+			return "@[card](" + node.URL + ")\n\n"
+		})
+		r.Register(notionapi.BlockTypeEmbed, renderURLLine)
+		r.Register(notionapi.BlockTypeVideo, renderURLLine)
+	},
+}
+
+// Qiita writes the front matter of Qiita CLI, :::note blocks for callouts,
+// details elements for toggles and link cards for bookmarks and embeds
+var Qiita = &Preset{
+	Name: "qiita",
+	FrontMatter: func(meta SiteMeta) string {
+		var result strings.Builder
+		result.WriteString("---\n")
+		result.WriteString("title: " + quoteString(meta.Title) + "\n")
+		if tags := blogTags(meta.Tags); len(tags) > 0 {
+			result.WriteString("tags:\n")
+			for _, tag := range tags {
+				result.WriteString("  - " + quoteString(tag) + "\n")
+			}
+		} else {
+			result.WriteString("tags: []\n")
+		}
+		result.WriteString("private: false\n")
+		result.WriteString("updated_at: \"\"\n")
+		result.WriteString("id: null\n")
+		result.WriteString("organization_url_name: null\n")
+		result.WriteString("slide: false\n")
+		fmt.Fprintf(&result, "ignorePublish: %t\n", meta.Draft)
+		result.WriteString("---\n\n")
+		return result.String()
+	},
+	Register: func(r *Renderer) {
+		r.Register(notionapi.BlockTypeCallout, func(r *Renderer, node *Node, depth int) string {
+			return renderColonBlock(r, node, "note "+calloutLevel(node), r.RichText(node.Text))
+		})
+		r.Register(notionapi.BlockTypeToggle, renderDetailsToggle(""))
+		r.Register(notionapi.BlockTypeBookmark, renderURLLine)
+		r.Register(notionapi.BlockTypeEmbed, renderURLLine)
+		r.Register(notionapi.BlockTypeVideo, renderURLLine)
+	},
+}
+
+// blogTags returns at most maxBlogTags tags
+func blogTags(tags []string) []string {
+	if len(tags) > maxBlogTags {
+		return tags[:maxBlogTags]
+	}
+	return tags
+}
+
+// calloutLevel classifies a callout by its icon as "info", "warn" or "alert"
+func calloutLevel(node *Node) string {
+	// Emoji may carry a variation selector
+	switch strings.TrimSuffix(node.Icon, "\ufe0f") {
+	case "⚠":
+		return "warn"
+	case "🚨", "❗", "‼", "❌", "⛔", "🛑":
+		return "alert"
+	}
+	return "info"
+}
+
+// renderColonBlock writes a callout as a :::kind block with its text and
+// nested blocks inside
+func renderColonBlock(r *Renderer, node *Node, kind, text string) string {
+	fence := colonFence(node)
+	body := joinLines(text, "\n")
+	if body != "" {
+		body += "\n\n"
+	}
+	return fence + kind + "\n" + body + blankLineAfter(r.Children(node, 0)) + fence + "\n\n"
+}
+
+// colonFence returns the fence of a ::: block. Blocks containing other
+// callouts or toggles use one more colon per nesting level so that the
+// inner fences do not close them.
+func colonFence(node *Node) string {
+	return strings.Repeat(":", 3+colonNesting(node.Children))
+}
+
+// colonNesting returns how deeply callouts and toggles are nested in nodes
+func colonNesting(nodes []*Node) int {
+	deepest := 0
+	for _, node := range nodes {
+		nesting := colonNesting(node.Children)
+		if node.Type == notionapi.BlockTypeCallout || node.Type == notionapi.BlockTypeToggle {
+			nesting++
+		}
+		deepest = max(deepest, nesting)
+	}
+	return deepest
+}
+
+// renderURLLine writes the URL of a bookmark or embed on a line of its own,
+// which Zenn and Qiita turn into a link card or an embedded player
+func renderURLLine(r *Renderer, node *Node, depth int) string {
+	if node.URL == "" {
+		return ""
+	}
+	return node.URL + "\n\n"
+}
@@ -0,0 +1,139 @@
+package notiontomd
+
+import (
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// Helper function to create a bookmark block
+func createBookmarkBlock(url string) notionapi.Block {
+	return &notionapi.BookmarkBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     "bookmark-1",
+			Type:   notionapi.BlockTypeBookmark,
+		},
+		Bookmark: notionapi.Bookmark{URL: url},
+	}
+}
+
+func TestBlogFrontMatter(t *testing.T) {
+	info := samplePageInfo()
+	info.Icon = "🐹"
+	info.Properties["Tags"] = Property{Type: "multi_select", Values: []string{"go", "notion", "zenn", "qiita", "cli", "markdown"}}
+	meta := NewSiteMeta(info, map[string]string{FieldDraft: "Status"})
+
+	tests := []struct {
+		preset   *Preset
+		expected string
+	}{
+		{
+			preset: Zenn,
+			expected: "---\n" +
+				"title: \"Say \\\"hello\\\"\"\n" +
+				"emoji: \"🐹\"\n" +
+				"type: \"tech\"\n" +
+				"topics: [\"go\", \"notion\", \"zenn\", \"qiita\", \"cli\"]\n" +
+				"published: false\n" +
+				"---\n\n",
+		},
+		{
+			preset: Qiita,
+			expected: "---\n" +
+				"title: \"Say \\\"hello\\\"\"\n" +
+				"tags:\n" +
+				"  - \"go\"\n" +
+				"  - \"notion\"\n" +
+				"  - \"zenn\"\n" +
+				"  - \"qiita\"\n" +
+				"  - \"cli\"\n" +
+				"private: false\n" +
+				"updated_at: \"\"\n" +
+				"id: null\n" +
+				"organization_url_name: null\n" +
+				"slide: false\n" +
+				"ignorePublish: true\n" +
+				"---\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset.Name, func(t *testing.T) {
+			result := tt.preset.FrontMatter(meta)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestZennDefaultEmoji(t *testing.T) {
+	result := Zenn.FrontMatter(NewSiteMeta(PageInfo{Title: "Page", Icon: "https://example.com/icon.png"}, nil))
+	expected := "---\ntitle: \"Page\"\nemoji: \"📝\"\ntype: \"tech\"\ntopics: []\npublished: true\n---\n\n"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestBlogBlocks(t *testing.T) {
+	warning := createCalloutBlock("Careful")
+	warning.(*notionapi.CalloutBlock).Callout.Icon = &notionapi.Icon{Type: "emoji", Emoji: ptrEmoji("⚠️")}
+
+	blocks := []BlockWithIndent{
+		{Block: createCalloutBlock("Heads up"), Indent: 0},
+		{Block: warning, Indent: 0},
+		{Block: createToggleBlock("More"), Indent: 0},
+		{Block: createBulletedListBlock("block-1", "Hidden", false), Indent: 1},
+		{Block: createBookmarkBlock("https://example.com"), Indent: 0},
+	}
+
+	tests := []struct {
+		preset   *Preset
+		expected string
+	}{
+		{
+			preset: Zenn,
+			expected: ":::message\nHeads up\n\n:::\n\n" +
+				":::message alert\nCareful\n\n:::\n\n" +
+				":::details More\n- Hidden\n\n:::\n\n" +
+				"@[card](https://example.com)\n\n",
+		},
+		{
+			preset: Qiita,
+			expected: ":::note info\nHeads up\n\n:::\n\n" +
+				":::note warn\nCareful\n\n:::\n\n" +
+				"<details>\n<summary>More</summary>\n\n- Hidden\n\n</details>\n\n" +
+				"https://example.com\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset.Name, func(t *testing.T) {
+			doc := (&Converter{opts: Options{Preset: tt.preset, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+			if doc.Content != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, doc.Content)
+			}
+		})
+	}
+}
+
+func TestZennNestedFences(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createToggleBlock("Outer"), Indent: 0},
+		{Block: createCalloutBlock("Inner"), Indent: 1},
+	}
+
+	doc := (&Converter{opts: Options{Preset: Zenn, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+	expected := "::::details Outer\n:::message\nInner\n\n:::\n\n::::\n\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+}
+
+// Helper function to create an emoji icon value
+func ptrEmoji(emoji string) *notionapi.Emoji {
+	e := notionapi.Emoji(emoji)
+	return &e
+}
@@ -0,0 +1,180 @@
+package notiontomd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/internal/atomicfile"
+)
+
+// DefaultCacheTTL is how long cached children responses are used by default
+const DefaultCacheTTL = 24 * time.Hour
+
+// FileURLMargin is how long before their expiry time the signed URLs of
+// files hosted by Notion are no longer reused. Cached responses holding such
+// URLs are fetched again after that, so downloads never see an expired URL.
+const FileURLMargin = 10 * time.Minute
+
+// EditSettleTime is how long after its last edited time a page may still
+// change without that time changing: Notion rounds it down to the minute,
+// and another minute allows for clock differences. Conversions of pages
+// edited more recently are not reused.
+const EditSettleTime = 2 * time.Minute
+
+// BlockCache stores block children responses on disk
+type BlockCache struct {
+	dir string
+	// ttl limits how long an entry is used. Zero means entries never expire.
+	ttl time.Duration
+	now func() time.Time
+}
+
+// cacheEntry is the on-disk form of a cached children response
+type cacheEntry struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	// ExpiresAt is when the first signed file URL in the response expires
+	ExpiresAt time.Time                      `json:"expires_at,omitzero"`
+	Response  *notionapi.GetChildrenResponse `json:"response"`
+}
+
+// NewBlockCache creates a cache storing its entries in dir
+func NewBlockCache(dir string, ttl time.Duration) *BlockCache {
+	return &BlockCache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// Fetcher wraps next so that the children of the blocks of a page are cached.
+// version is the last edited time of the page: Notion updates it whenever any
+// block of the page changes, so entries of older versions are never used.
+// A nil cache, or a page edited within EditSettleTime, returns next unchanged.
+func (c *BlockCache) Fetcher(next BlockFetcher, version time.Time) BlockFetcher {
+	if c == nil || c.now().Sub(version) < EditSettleTime {
+		return next
+	}
+	return &cachingBlockFetcher{next: next, cache: c, version: version}
+}
+
+// path returns the file of the entry for the given key
+func (c *BlockCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name[:2], name+".json")
+}
+
+// load returns the cached response for key, or nil when there is no usable entry
+func (c *BlockCache) load(key string) *notionapi.GetChildrenResponse {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Response == nil {
+		return nil
+	}
+	if c.ttl > 0 && c.now().Sub(entry.FetchedAt) > c.ttl {
+		return nil
+	}
+	if !entry.ExpiresAt.IsZero() && !c.now().Before(entry.ExpiresAt.Add(-FileURLMargin)) {
+		return nil
+	}
+	return entry.Response
+}
+
+// store saves the response for key. Failures only cost a later cache miss.
+// Responses with file URLs are kept until the first of them expires, and
+// not at all when that is too soon or unknown.
+func (c *BlockCache) store(key string, resp *notionapi.GetChildrenResponse) {
+	entry := cacheEntry{FetchedAt: c.now(), Response: resp}
+	if expires, ok := FilesExpireAt(resp.Results); ok {
+		if !entry.FetchedAt.Before(expires.Add(-FileURLMargin)) {
+			return
+		}
+		entry.ExpiresAt = expires
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	atomicfile.WriteFile(path, data, 0o644)
+}
+
+// cachingBlockFetcher is a BlockFetcher decorator backed by a BlockCache
+type cachingBlockFetcher struct {
+	next    BlockFetcher
+	cache   *BlockCache
+	version time.Time
+}
+
+// GetChildren returns the cached response when there is one and fetches and stores it otherwise
+func (f *cachingBlockFetcher) GetChildren(ctx context.Context, blockID notionapi.BlockID, pagination *notionapi.Pagination) (*notionapi.GetChildrenResponse, error) {
+	var cursor notionapi.Cursor
+	if pagination != nil {
+		cursor = pagination.StartCursor
+	}
+	key := fmt.Sprintf("%s@%s#%s", blockID, f.version.UTC().Format(time.RFC3339Nano), cursor)
+
+	if resp := f.cache.load(key); resp != nil {
+		return resp, nil
+	}
+
+	resp, err := f.next.GetChildren(ctx, blockID, pagination)
+	if err != nil {
+		return nil, err
+	}
+	f.cache.store(key, resp)
+	return resp, nil
+}
+
+// FilesExpireAt returns when the first signed URL of a file hosted by Notion
+// in blocks expires, and false when blocks hold no such file. A file without
+// an expiry time counts as already expired.
+func FilesExpireAt(blocks []notionapi.Block) (time.Time, bool) {
+	var expires time.Time
+	found := false
+	for _, block := range blocks {
+		file := hostedFile(block)
+		if file == nil {
+			continue
+		}
+		if file.ExpiryTime == nil {
+			return time.Time{}, true
+		}
+		if !found || file.ExpiryTime.Before(expires) {
+			expires = *file.ExpiryTime
+		}
+		found = true
+	}
+	return expires, found
+}
+
+// hostedFile returns the file of a block that is hosted by Notion, or nil
+func hostedFile(block notionapi.Block) *notionapi.FileObject {
+	switch b := block.(type) {
+	case *notionapi.ImageBlock:
+		return b.Image.File
+	case *notionapi.FileBlock:
+		return b.File.File
+	case *notionapi.PdfBlock:
+		return b.Pdf.File
+	case *notionapi.VideoBlock:
+		return b.Video.File
+	case *notionapi.AudioBlock:
+		return b.Audio.File
+	case *notionapi.CalloutBlock:
+		if b.Callout.Icon != nil {
+			return b.Callout.Icon.File
+		}
+	}
+	return nil
+}
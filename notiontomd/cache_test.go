@@ -0,0 +1,197 @@
+package notiontomd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestCachingBlockFetcherHit(t *testing.T) {
+	ctx := context.Background()
+	cache := NewBlockCache(t.TempDir(), time.Hour)
+	version := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+
+	mock := &mockBlockFetcher{
+		responses: []*notionapi.GetChildrenResponse{
+			{
+				Results: []notionapi.Block{createBulletedListBlock("block-1", "Cached item", false)},
+				HasMore: false,
+			},
+		},
+	}
+
+	first, err := cache.Fetcher(mock, version).GetChildren(ctx, "page-1", &notionapi.Pagination{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A second fetcher for the same page version must not call the API
+	mock.err = errors.New("API must not be called")
+	second, err := cache.Fetcher(mock, version).GetChildren(ctx, "page-1", &notionapi.Pagination{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if mock.callCount != 1 {
+		t.Errorf("Expected 1 API call, got %d", mock.callCount)
+	}
+	if len(second.Results) != len(first.Results) {
+		t.Fatalf("Expected %d cached blocks, got %d", len(first.Results), len(second.Results))
+	}
+
+	block, ok := second.Results[0].(*notionapi.BulletedListItemBlock)
+	if !ok {
+		t.Fatalf("Expected BulletedListItemBlock, got %T", second.Results[0])
+	}
+	if text := block.BulletedListItem.RichText[0].PlainText; text != "Cached item" {
+		t.Errorf("Expected %q, got %q", "Cached item", text)
+	}
+}
+
+func TestCachingBlockFetcherMiss(t *testing.T) {
+	ctx := context.Background()
+	version := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		version time.Time
+		cursor  notionapi.Cursor
+		age     time.Duration
+	}{
+		{"Page edited", version.Add(time.Minute), "", 0},
+		{"Different cursor", version, "cursor-1", 0},
+		{"Expired", version, "", 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewBlockCache(t.TempDir(), time.Hour)
+			mock := &mockBlockFetcher{
+				responses: []*notionapi.GetChildrenResponse{
+					{Results: []notionapi.Block{createParagraphBlock("block-1", false)}},
+					{Results: []notionapi.Block{createParagraphBlock("block-1", false)}},
+				},
+			}
+
+			if _, err := cache.Fetcher(mock, version).GetChildren(ctx, "page-1", &notionapi.Pagination{}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			fetchedAt := time.Now()
+			cache.now = func() time.Time { return fetchedAt.Add(tt.age) }
+			if _, err := cache.Fetcher(mock, tt.version).GetChildren(ctx, "page-1", &notionapi.Pagination{StartCursor: tt.cursor}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if mock.callCount != 2 {
+				t.Errorf("Expected 2 API calls, got %d", mock.callCount)
+			}
+		})
+	}
+}
+
+func TestNilBlockCacheFetcher(t *testing.T) {
+	var cache *BlockCache
+	mock := &mockBlockFetcher{}
+
+	if cache.Fetcher(mock, time.Now()) != BlockFetcher(mock) {
+		t.Error("Expected a nil cache to return the wrapped fetcher")
+	}
+}
+
+func TestBlockCacheRecentlyEdited(t *testing.T) {
+	cache := NewBlockCache(t.TempDir(), time.Hour)
+	now := time.Date(2024, 1, 2, 15, 30, 40, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	mock := &mockBlockFetcher{}
+
+	// The page may still change within the minute its last edited time names
+	if cache.Fetcher(mock, now.Truncate(time.Minute)) != BlockFetcher(mock) {
+		t.Error("Expected a recently edited page not to be cached")
+	}
+	if cache.Fetcher(mock, now.Add(-EditSettleTime)) == BlockFetcher(mock) {
+		t.Error("Expected a settled page to be cached")
+	}
+}
+
+// Helper function to create an image block hosted by Notion
+func createHostedImageBlock(id string, expires *time.Time) notionapi.Block {
+	return &notionapi.ImageBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     notionapi.BlockID(id),
+			Type:   notionapi.BlockTypeImage,
+		},
+		Image: notionapi.Image{
+			Type: notionapi.FileTypeFile,
+			File: &notionapi.FileObject{URL: "https://files.example.com/" + id + ".png", ExpiryTime: expires},
+		},
+	}
+}
+
+func TestCachingBlockFetcherFileExpiry(t *testing.T) {
+	ctx := context.Background()
+	version := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+	now := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	inHour := now.Add(time.Hour)
+	soon := now.Add(FileURLMargin / 2)
+
+	tests := []struct {
+		name     string
+		block    notionapi.Block
+		age      time.Duration
+		expected int
+	}{
+		{"External file", createImageBlock("block-1", "https://example.com/a.png"), 2 * time.Hour, 1},
+		{"Before the URL expires", createHostedImageBlock("block-1", &inHour), 30 * time.Minute, 1},
+		{"Close to the URL expiry", createHostedImageBlock("block-1", &inHour), time.Hour - FileURLMargin, 2},
+		{"URL expiring soon", createHostedImageBlock("block-1", &soon), 0, 2},
+		{"Unknown URL expiry", createHostedImageBlock("block-1", nil), 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewBlockCache(t.TempDir(), 24*time.Hour)
+			cache.now = func() time.Time { return now }
+			mock := &mockBlockFetcher{
+				responses: []*notionapi.GetChildrenResponse{
+					{Results: []notionapi.Block{tt.block}},
+					{Results: []notionapi.Block{tt.block}},
+				},
+			}
+
+			if _, err := cache.Fetcher(mock, version).GetChildren(ctx, "page-1", &notionapi.Pagination{}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			cache.now = func() time.Time { return now.Add(tt.age) }
+			if _, err := cache.Fetcher(mock, version).GetChildren(ctx, "page-1", &notionapi.Pagination{}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if mock.callCount != tt.expected {
+				t.Errorf("Expected %d API calls, got %d", tt.expected, mock.callCount)
+			}
+		})
+	}
+}
+
+func TestFilesExpireAt(t *testing.T) {
+	early := time.Date(2024, 1, 3, 1, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	expires, ok := FilesExpireAt([]notionapi.Block{
+		createParagraphBlock("block-1", false),
+		createHostedImageBlock("block-2", &late),
+		createHostedImageBlock("block-3", &early),
+	})
+	if !ok || !expires.Equal(early) {
+		t.Errorf("Expected %v, got %v (%t)", early, expires, ok)
+	}
+
+	if _, ok := FilesExpireAt([]notionapi.Block{createImageBlock("block-1", "https://example.com/a.png")}); ok {
+		t.Error("Expected no hosted files")
+	}
+}
@@ -0,0 +1,154 @@
+package notiontomd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// PageInfo holds metadata about a Notion page
+type PageInfo struct {
+	ID             notionapi.BlockID `json:"id"`
+	Title          string            `json:"title"`
+	URL            string            `json:"url"`
+	CreatedTime    time.Time         `json:"created_time"`
+	LastEditedTime time.Time         `json:"last_edited_time"`
+	// Icon is the emoji or icon URL of the page
+	Icon string `json:"icon,omitempty"`
+	// Properties are the page properties by name, including the title
+	Properties map[string]Property `json:"properties,omitempty"`
+}
+
+// truncatedMarker is written in place of blocks omitted by depth truncation
+const truncatedMarker = "*(truncated: nested blocks omitted)*"
+
+// GenerateFrontMatter generates YAML front-matter from page metadata
+func GenerateFrontMatter(info PageInfo) string {
+	var result strings.Builder
+
+	result.WriteString("---\n")
+	result.WriteString("title: \"" + info.Title + "\"\n")
+	result.WriteString("url: " + info.URL + "\n")
+	result.WriteString("created: " + info.CreatedTime.Format(time.RFC3339) + "\n")
+	result.WriteString("updated: " + info.LastEditedTime.Format(time.RFC3339) + "\n")
+	result.WriteString("---\n\n")
+
+	return result.String()
+}
+
+// Convert converts blocks with indentation to Markdown using the built-in renderers
+func Convert(blocks []BlockWithIndent) string {
+	return NewRenderer().Render(NewNodes(blocks))
+}
+
+// defaultBlockRenderers returns the built-in renderers keyed by block type
+func defaultBlockRenderers() map[notionapi.BlockType]BlockRenderer {
+	return map[notionapi.BlockType]BlockRenderer{
+		notionapi.BlockTypeHeading1:         renderHeading("# ", "<br>"),
+		notionapi.BlockTypeHeading2:         renderHeading("## ", "<br>"),
+		notionapi.BlockTypeHeading3:         renderHeading("### ", "<br>"),
+		notionapi.BlockTypeParagraph:        renderParagraph,
+		notionapi.BlockTypeBulletedListItem: renderBulletedListItem,
+		notionapi.BlockTypeNumberedListItem: renderNumberedListItem,
+		notionapi.BlockTypeCode:             renderCode,
+		notionapi.BlockTypeToggle:           renderToggle,
+		notionapi.BlockTypeQuote:            renderQuote,
+		notionapi.BlockTypeDivider:          renderDivider,
+		notionapi.BlockTypeCallout:          renderCallout,
+	}
+}
+
+// renderHeading returns a renderer for headings with the given prefix.
+// Line breaks within the heading become lineBreak.
+func renderHeading(prefix, lineBreak string) BlockRenderer {
+	return func(r *Renderer, node *Node, depth int) string {
+		text := joinLines(r.RichText(node.Text), lineBreak)
+		return prefix + text + "\n\n" + r.Children(node, depth+1)
+	}
+}
+
+func renderParagraph(r *Renderer, node *Node, depth int) string {
+	text := r.RichText(node.Text)
+	if text == "" {
+		return r.Children(node, depth+1)
+	}
+	return text + "\n\n" + r.Children(node, depth+1)
+}
+
+func renderBulletedListItem(r *Renderer, node *Node, depth int) string {
+	// Continuation lines use a hard break and are aligned with the item text
+	indent := Indent(depth)
+	text := joinLines(r.RichText(node.Text), "  \n"+indent+"  ")
+	return indent + "- " + text + "\n" + r.Children(node, depth+1)
+}
+
+func renderNumberedListItem(r *Renderer, node *Node, depth int) string {
+	indent := Indent(depth)
+	text := joinLines(r.RichText(node.Text), "  \n"+indent+"   ")
+	return indent + "1. " + text + "\n" + r.Children(node, depth+1)
+}
+
+func renderCode(r *Renderer, node *Node, depth int) string {
+	text := r.RichText(node.Text)
+	return "```" + node.Language + "\n" + text + "\n" + "```\n\n"
+}
+
+func renderToggle(r *Renderer, node *Node, depth int) string {
+	indent := Indent(depth)
+	text := joinLines(r.RichText(node.Text), "  \n"+indent+"  ")
+	return indent + "- " + text + "\n" + r.Children(node, depth+1)
+}
+
+func renderQuote(r *Renderer, node *Node, depth int) string {
+	text := joinLines(r.RichText(node.Text), "\n> ")
+	return "> " + text + "\n\n" + r.Children(node, depth+1)
+}
+
+func renderDivider(r *Renderer, node *Node, depth int) string {
+	return "---\n\n"
+}
+
+func renderCallout(r *Renderer, node *Node, depth int) string {
+	text := joinLines(r.RichText(node.Text), "\n> ")
+	return "> " + text + "\n\n" + r.Children(node, depth+1)
+}
+
+// joinLines joins the lines of text with sep so that multi-line rich text
+// stays inside the block it belongs to. Trailing newlines are dropped.
+func joinLines(text, sep string) string {
+	return strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", sep)
+}
+
+// FormatRichText converts rich text to Markdown with annotations.
+// It is the default rich-text renderer.
+func FormatRichText(spans []Span) string {
+	var result strings.Builder
+
+	for _, span := range spans {
+		text := span.Text
+
+		// Apply annotations in order: code, bold, italic, strikethrough
+		if span.Code {
+			text = "`" + text + "`"
+		}
+		if span.Bold {
+			text = "**" + text + "**"
+		}
+		if span.Italic {
+			text = "*" + text + "*"
+		}
+		if span.Strikethrough {
+			text = "~~" + text + "~~"
+		}
+
+		// Apply link
+		if span.Href != "" {
+			text = "[" + text + "](" + span.Href + ")"
+		}
+
+		result.WriteString(text)
+	}
+
+	return result.String()
+}
@@ -1,4 +1,4 @@
-package main
+package notiontomd
 
 import (
 	"testing"
@@ -27,7 +27,7 @@ func TestConvertHeading1(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "# Test Heading\n\n"
 
 	if result != expected {
@@ -55,7 +55,7 @@ func TestConvertHeading2(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "## Test Heading\n\n"
 
 	if result != expected {
@@ -83,7 +83,7 @@ func TestConvertHeading3(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "### Test Heading\n\n"
 
 	if result != expected {
@@ -111,7 +111,7 @@ func TestConvertParagraph(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "Test paragraph\n\n"
 
 	if result != expected {
@@ -139,7 +139,7 @@ func TestConvertBulletedListItem(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "- List item\n"
 
 	if result != expected {
@@ -167,7 +167,7 @@ func TestConvertNumberedListItem(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "1. Numbered item\n"
 
 	if result != expected {
@@ -196,7 +196,7 @@ func TestConvertCode(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "```go\nfunc main() {}\n```\n\n"
 
 	if result != expected {
@@ -224,7 +224,7 @@ func TestConvertToggle(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "- Toggle item\n"
 
 	if result != expected {
@@ -252,7 +252,7 @@ func TestConvertQuote(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "> Quote text\n\n"
 
 	if result != expected {
@@ -280,7 +280,7 @@ func TestConvertCallout(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "> Callout text\n\n"
 
 	if result != expected {
@@ -301,7 +301,7 @@ func TestConvertDivider(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "---\n\n"
 
 	if result != expected {
@@ -332,7 +332,7 @@ func TestConvertBoldAnnotation(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "**bold text**\n\n"
 
 	if result != expected {
@@ -363,7 +363,7 @@ func TestConvertItalicAnnotation(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "*italic text*\n\n"
 
 	if result != expected {
@@ -394,7 +394,7 @@ func TestConvertCodeAnnotation(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "`code text`\n\n"
 
 	if result != expected {
@@ -425,7 +425,7 @@ func TestConvertStrikethroughAnnotation(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "~~strikethrough text~~\n\n"
 
 	if result != expected {
@@ -457,7 +457,7 @@ func TestConvertMultipleAnnotations(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "***bold italic***\n\n"
 
 	if result != expected {
@@ -486,7 +486,7 @@ func TestConvertLink(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "[link text](https://example.com)\n\n"
 
 	if result != expected {
@@ -530,7 +530,7 @@ func TestConvertNestedList(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "- Parent item\n  - Child item\n"
 
 	if result != expected {
@@ -574,7 +574,7 @@ func TestConvertMultipleBlocks(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "# Title\n\nContent\n\n"
 
 	if result != expected {
@@ -598,7 +598,7 @@ func TestConvertEmptyParagraph(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := ""
 
 	if result != expected {
@@ -635,7 +635,7 @@ func TestConvertMultipleRichTexts(t *testing.T) {
 		},
 	}
 
-	result := convert(blocks)
+	result := Convert(blocks)
 	expected := "Normal **bold** text\n\n"
 
 	if result != expected {
@@ -654,7 +654,7 @@ func TestGenerateFrontMatter(t *testing.T) {
 		LastEditedTime: updatedTime,
 	}
 
-	result := generateFrontMatter(pageInfo)
+	result := GenerateFrontMatter(pageInfo)
 	expected := "---\n" +
 		"title: \"Test Page Title\"\n" +
 		"url: https://www.notion.so/test-page\n" +
@@ -678,7 +678,7 @@ func TestGenerateFrontMatterWithEmptyTitle(t *testing.T) {
 		LastEditedTime: updatedTime,
 	}
 
-	result := generateFrontMatter(pageInfo)
+	result := GenerateFrontMatter(pageInfo)
 	expected := "---\n" +
 		"title: \"\"\n" +
 		"url: https://www.notion.so/untitled\n" +
@@ -690,3 +690,161 @@ func TestGenerateFrontMatterWithEmptyTitle(t *testing.T) {
 		t.Errorf("Expected %q, got %q", expected, result)
 	}
 }
+
+func TestConvertMultiLineQuote(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{
+			Block: &notionapi.QuoteBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object: "block",
+					Type:   notionapi.BlockTypeQuote,
+				},
+				Quote: notionapi.Quote{
+					RichText: []notionapi.RichText{
+						{
+							PlainText: "First line\nSecond line\n",
+						},
+					},
+				},
+			},
+			Indent: 0,
+		},
+	}
+
+	result := Convert(blocks)
+	expected := "> First line\n> Second line\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertMultiLineCallout(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{
+			Block: &notionapi.CalloutBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object: "block",
+					Type:   notionapi.BlockTypeCallout,
+				},
+				Callout: notionapi.Callout{
+					RichText: []notionapi.RichText{
+						{
+							PlainText: "First line\nSecond line",
+						},
+					},
+				},
+			},
+			Indent: 0,
+		},
+	}
+
+	result := Convert(blocks)
+	expected := "> First line\n> Second line\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertMultiLineNestedListItems(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{
+			Block: &notionapi.BulletedListItemBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object: "block",
+					Type:   notionapi.BlockTypeBulletedListItem,
+				},
+				BulletedListItem: notionapi.ListItem{
+					RichText: []notionapi.RichText{
+						{
+							PlainText: "Parent\ncontinued",
+						},
+					},
+				},
+			},
+			Indent: 0,
+		},
+		{
+			Block: &notionapi.NumberedListItemBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object: "block",
+					Type:   notionapi.BlockTypeNumberedListItem,
+				},
+				NumberedListItem: notionapi.ListItem{
+					RichText: []notionapi.RichText{
+						{
+							PlainText: "Child\ncontinued",
+						},
+					},
+				},
+			},
+			Indent: 1,
+		},
+	}
+
+	result := Convert(blocks)
+	expected := "- Parent  \n  continued\n" +
+		"  1. Child  \n     continued\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertMultiLineHeading(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{
+			Block: &notionapi.Heading2Block{
+				BasicBlock: notionapi.BasicBlock{
+					Object: "block",
+					Type:   notionapi.BlockTypeHeading2,
+				},
+				Heading2: notionapi.Heading{
+					RichText: []notionapi.RichText{
+						{
+							PlainText: "Line one\nLine two",
+						},
+					},
+				},
+			},
+			Indent: 0,
+		},
+	}
+
+	result := Convert(blocks)
+	expected := "## Line one<br>Line two\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertTruncatedBlock(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{
+			Block: &notionapi.BulletedListItemBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object: "block",
+					Type:   notionapi.BlockTypeBulletedListItem,
+				},
+				BulletedListItem: notionapi.ListItem{
+					RichText: []notionapi.RichText{
+						{
+							PlainText: "Deep item",
+						},
+					},
+				},
+			},
+			Indent:    1,
+			Truncated: true,
+		},
+	}
+
+	result := Convert(blocks)
+	expected := "  - Deep item\n    - *(truncated: nested blocks omitted)*\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,323 @@
+package notiontomd
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// EPUBOptions configures WriteEPUB
+type EPUBOptions struct {
+	// Language is the BCP 47 language of the book. Empty means "und".
+	Language string
+	// Download fetches the images embedded in the book. Nil keeps images as
+	// links to their URL, which most readers do not display.
+	Download func(ctx context.Context, url string) ([]byte, error)
+}
+
+// epubImage is an image stored in the book
+type epubImage struct {
+	path      string
+	mediaType string
+	data      []byte
+}
+
+// epubHeading is a heading listed in the navigation document
+type epubHeading struct {
+	level int
+	id    string
+	text  string
+}
+
+// epubChapter is a page written as a content document
+type epubChapter struct {
+	file     string
+	title    string
+	headings []epubHeading
+	content  string
+	// remote is set when the chapter links to images outside the book
+	remote bool
+}
+
+// WriteEPUB writes the documents as an EPUB 3 book with one chapter per
+// document, in order. The metadata of the book is taken from the first
+// document, and the navigation document lists the title and headings of
+// every chapter.
+func WriteEPUB(ctx context.Context, w io.Writer, docs []Document, opts EPUBOptions) error {
+	if len(docs) == 0 {
+		return fmt.Errorf("failed to write EPUB: no pages")
+	}
+
+	images := make(map[string]*epubImage)
+	var order []*epubImage
+	chapters := make([]epubChapter, len(docs))
+	for i, doc := range docs {
+		nodes, err := embedImages(ctx, doc.Nodes, opts.Download, images, &order)
+		if err != nil {
+			return err
+		}
+		chapters[i] = newEPUBChapter(fmt.Sprintf("chapter-%03d.xhtml", i+1), doc.Info, nodes, opts.Download == nil)
+	}
+
+	zw := zip.NewWriter(w)
+	// The mimetype must be the first entry and stored uncompressed
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("failed to write EPUB: %w", err)
+	}
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return fmt.Errorf("failed to write EPUB: %w", err)
+	}
+
+	add := func(name string, data []byte) error {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to write EPUB: %w", err)
+		}
+		if _, err := fw.Write(data); err != nil {
+			return fmt.Errorf("failed to write EPUB: %w", err)
+		}
+		return nil
+	}
+	if err := add("META-INF/container.xml", []byte(epubContainer)); err != nil {
+		return err
+	}
+	if err := add("OEBPS/content.opf", []byte(epubPackage(docs[0].Info, opts.Language, chapters, order))); err != nil {
+		return err
+	}
+	if err := add("OEBPS/nav.xhtml", []byte(epubNav(docs[0].Info.Title, chapters))); err != nil {
+		return err
+	}
+	if err := add("OEBPS/style.css", []byte(htmlStyle)); err != nil {
+		return err
+	}
+	for _, chapter := range chapters {
+		if err := add("OEBPS/"+chapter.file, []byte(chapter.content)); err != nil {
+			return err
+		}
+	}
+	for _, image := range order {
+		if err := add("OEBPS/"+image.path, image.data); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write EPUB: %w", err)
+	}
+	return nil
+}
+
+// epubContainer points reading systems to the package document
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// embedImages returns copies of the nodes whose images point to files in
+// the book, downloading each URL once into images
+func embedImages(ctx context.Context, nodes []*Node, download func(context.Context, string) ([]byte, error), images map[string]*epubImage, order *[]*epubImage) ([]*Node, error) {
+	if download == nil {
+		return nodes, nil
+	}
+
+	result := make([]*Node, len(nodes))
+	for i, node := range nodes {
+		copied := *node
+		if node.Type == notionapi.BlockTypeImage && node.URL != "" {
+			image, ok := images[node.URL]
+			if !ok {
+				data, err := download(ctx, node.URL)
+				if err != nil {
+					return nil, fmt.Errorf("failed to download image %s: %w", node.URL, err)
+				}
+				name := AttachmentName(node)
+				mediaType := mime.TypeByExtension(path.Ext(name))
+				if mediaType == "" {
+					mediaType = http.DetectContentType(data)
+				}
+				image = &epubImage{path: "images/" + name, mediaType: strings.Split(mediaType, ";")[0], data: data}
+				images[node.URL] = image
+				*order = append(*order, image)
+			}
+			copied.URL = image.path
+		}
+		children, err := embedImages(ctx, node.Children, download, images, order)
+		if err != nil {
+			return nil, err
+		}
+		copied.Children = children
+		result[i] = &copied
+	}
+	return result, nil
+}
+
+// xhtmlVoidElement matches the void elements written by the HTML renderer
+var xhtmlVoidElement = regexp.MustCompile(`<(br|hr|img|input)([^>]*)>`)
+
+// xhtmlHeading matches the opening tags of headings written by the HTML renderer
+var xhtmlHeading = regexp.MustCompile(`<h([123])>`)
+
+// newEPUBChapter renders a page as an XHTML content document. Headings get
+// IDs so that the navigation document can link to them. remote marks
+// chapters whose images were not downloaded.
+func newEPUBChapter(file string, info PageInfo, nodes []*Node, remote bool) epubChapter {
+	chapter := epubChapter{file: file, title: info.Title, remote: remote && hasImages(nodes)}
+	var count int
+	collectHeadings(nodes, &chapter.headings, &count)
+
+	body := RenderHTML(nodes)
+	body = strings.NewReplacer(" checked disabled>", " checked=\"checked\" disabled=\"disabled\">", " disabled>", " disabled=\"disabled\">").Replace(body)
+	body = xhtmlVoidElement.ReplaceAllString(body, "<$1$2/>")
+	// Headings are rendered in the order they were collected
+	n := 0
+	body = xhtmlHeading.ReplaceAllStringFunc(body, func(tag string) string {
+		n++
+		return tag[:3] + " id=\"section-" + strconv.Itoa(n) + "\">"
+	})
+
+	var result strings.Builder
+	result.WriteString(xhtmlHeader(info.Title))
+	result.WriteString("<h1 class=\"title\">" + html.EscapeString(info.Title) + "</h1>\n")
+	result.WriteString(body)
+	result.WriteString("</body>\n</html>\n")
+	chapter.content = result.String()
+	return chapter
+}
+
+// collectHeadings appends the headings in document order, the order they
+// are rendered in. n counts every heading, including the empty ones that
+// are left out of the navigation document.
+func collectHeadings(nodes []*Node, headings *[]epubHeading, n *int) {
+	for _, node := range nodes {
+		if level := headingLevel(node.Type); level > 0 {
+			*n++
+			if text := PlainText(node.Text); text != "" {
+				*headings = append(*headings, epubHeading{level: level, id: "section-" + strconv.Itoa(*n), text: text})
+			}
+		}
+		collectHeadings(node.Children, headings, n)
+	}
+}
+
+// hasImages reports whether any of the nodes is an image
+func hasImages(nodes []*Node) bool {
+	for _, node := range nodes {
+		if node.Type == notionapi.BlockTypeImage || hasImages(node.Children) {
+			return true
+		}
+	}
+	return false
+}
+
+// xhtmlHeader starts an XHTML content document
+func xhtmlHeader(title string) string {
+	return "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+		"<!DOCTYPE html>\n" +
+		"<html xmlns=\"http://www.w3.org/1999/xhtml\" xmlns:epub=\"http://www.idpf.org/2007/ops\">\n" +
+		"<head>\n" +
+		"<meta charset=\"utf-8\"/>\n" +
+		"<title>" + html.EscapeString(title) + "</title>\n" +
+		"<link rel=\"stylesheet\" type=\"text/css\" href=\"style.css\"/>\n" +
+		"</head>\n<body>\n"
+}
+
+// epubPackage writes the package document with the metadata and manifest
+func epubPackage(info PageInfo, language string, chapters []epubChapter, images []*epubImage) string {
+	if language == "" {
+		language = "und"
+	}
+
+	var result strings.Builder
+	result.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	result.WriteString("<package xmlns=\"http://www.idpf.org/2007/opf\" version=\"3.0\" unique-identifier=\"book-id\">\n")
+	result.WriteString("  <metadata xmlns:dc=\"http://purl.org/dc/elements/1.1/\">\n")
+	result.WriteString("    <dc:identifier id=\"book-id\">urn:uuid:" + html.EscapeString(string(info.ID)) + "</dc:identifier>\n")
+	result.WriteString("    <dc:title>" + html.EscapeString(info.Title) + "</dc:title>\n")
+	result.WriteString("    <dc:language>" + html.EscapeString(language) + "</dc:language>\n")
+	if !info.CreatedTime.IsZero() {
+		result.WriteString("    <dc:date>" + info.CreatedTime.UTC().Format(time.RFC3339) + "</dc:date>\n")
+	}
+	if info.URL != "" {
+		result.WriteString("    <dc:source>" + html.EscapeString(info.URL) + "</dc:source>\n")
+	}
+	result.WriteString("    <meta property=\"dcterms:modified\">" + info.LastEditedTime.UTC().Format("2006-01-02T15:04:05Z") + "</meta>\n")
+	result.WriteString("  </metadata>\n")
+
+	result.WriteString("  <manifest>\n")
+	result.WriteString("    <item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n")
+	result.WriteString("    <item id=\"style\" href=\"style.css\" media-type=\"text/css\"/>\n")
+	for i, chapter := range chapters {
+		properties := ""
+		if chapter.remote {
+			properties = " properties=\"remote-resources\""
+		}
+		fmt.Fprintf(&result, "    <item id=\"chapter-%d\" href=\"%s\" media-type=\"application/xhtml+xml\"%s/>\n", i+1, chapter.file, properties)
+	}
+	for i, image := range images {
+		fmt.Fprintf(&result, "    <item id=\"image-%d\" href=\"%s\" media-type=\"%s\"/>\n", i+1, html.EscapeString(image.path), html.EscapeString(image.mediaType))
+	}
+	result.WriteString("  </manifest>\n")
+
+	result.WriteString("  <spine>\n")
+	for i := range chapters {
+		fmt.Fprintf(&result, "    <itemref idref=\"chapter-%d\"/>\n", i+1)
+	}
+	result.WriteString("  </spine>\n")
+	result.WriteString("</package>\n")
+
+	return result.String()
+}
+
+// epubNav writes the navigation document: one entry per chapter with its
+// headings nested by level
+func epubNav(title string, chapters []epubChapter) string {
+	var result strings.Builder
+	result.WriteString(xhtmlHeader(title))
+	result.WriteString("<nav epub:type=\"toc\" id=\"toc\">\n")
+	result.WriteString("<h1>" + html.EscapeString(title) + "</h1>\n")
+	result.WriteString("<ol>\n")
+	for _, chapter := range chapters {
+		result.WriteString("<li><a href=\"" + chapter.file + "\">" + html.EscapeString(chapter.title) + "</a>")
+		writeNavHeadings(&result, chapter.file, chapter.headings)
+		result.WriteString("</li>\n")
+	}
+	result.WriteString("</ol>\n</nav>\n")
+	result.WriteString("</body>\n</html>\n")
+	return result.String()
+}
+
+// writeNavHeadings writes headings as nested ordered lists. A heading is
+// nested under the closest preceding heading of a higher level.
+func writeNavHeadings(w *strings.Builder, file string, headings []epubHeading) {
+	var levels []int
+	for _, heading := range headings {
+		for len(levels) > 1 && heading.level < levels[len(levels)-1] {
+			w.WriteString("</li>\n</ol>\n")
+			levels = levels[:len(levels)-1]
+		}
+		if len(levels) == 0 || heading.level > levels[len(levels)-1] {
+			w.WriteString("\n<ol>\n")
+			levels = append(levels, heading.level)
+		} else {
+			w.WriteString("</li>\n")
+		}
+		w.WriteString("<li><a href=\"" + file + "#" + heading.id + "\">" + html.EscapeString(heading.text) + "</a>")
+	}
+	for range levels {
+		w.WriteString("</li>\n</ol>\n")
+	}
+}
@@ -0,0 +1,155 @@
+package notiontomd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// Helper function to read the files of an EPUB by name
+func readEPUB(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Expected a zip archive, got %v", err)
+	}
+	files := make(map[string]string)
+	var names []string
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		files[f.Name] = string(content)
+		names = append(names, f.Name)
+	}
+	if len(zr.File) == 0 || zr.File[0].Name != "mimetype" || zr.File[0].Method != zip.Store {
+		t.Errorf("Expected an uncompressed mimetype as the first entry, got %v", names)
+	}
+	return files
+}
+
+// Helper function to check that a document is well-formed XML
+func checkXML(t *testing.T, name string, content string) {
+	t.Helper()
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if err != io.EOF {
+				t.Errorf("%s: expected well-formed XML, got %v\n%s", name, err, content)
+			}
+			return
+		}
+	}
+}
+
+func TestWriteEPUB(t *testing.T) {
+	conv := &Converter{opts: Options{Format: FormatHTML, OmitFrontMatter: true}}
+	info := samplePageInfo()
+	root := conv.mustRender(t, info, []BlockWithIndent{
+		{Block: createHeading1Block("Intro"), Indent: 0},
+		{Block: createTextParagraphBlock("It's <new>"), Indent: 0},
+		{Block: createImageBlock("0c1d2e3f-4a5b-6c7d-8e9f-000000000000", "https://example.com/diagram.png"), Indent: 0},
+		{Block: &notionapi.Heading2Block{
+			BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeHeading2},
+			Heading2:   notionapi.Heading{RichText: []notionapi.RichText{{Type: "text", PlainText: "Details"}}},
+		}, Indent: 0},
+		{Block: createDividerBlock(), Indent: 0},
+	})
+	child := conv.mustRender(t, PageInfo{ID: "page-2", Title: "Child"}, []BlockWithIndent{
+		{Block: createHeading1Block("Usage"), Indent: 0},
+	})
+
+	var downloads []string
+	var buf bytes.Buffer
+	err := WriteEPUB(context.Background(), &buf, []Document{root, child}, EPUBOptions{
+		Language: "ja",
+		Download: func(ctx context.Context, url string) ([]byte, error) {
+			downloads = append(downloads, url)
+			return []byte("\x89PNG\r\n\x1a\n"), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	files := readEPUB(t, buf.Bytes())
+	for name, content := range files {
+		if strings.HasSuffix(name, ".xhtml") || strings.HasSuffix(name, ".opf") || strings.HasSuffix(name, ".xml") {
+			checkXML(t, name, content)
+		}
+	}
+
+	if len(downloads) != 1 || downloads[0] != "https://example.com/diagram.png" {
+		t.Errorf("Expected the image to be downloaded once, got %v", downloads)
+	}
+	if _, ok := files["OEBPS/images/diagram-0c1d2e3f.png"]; !ok {
+		t.Error("Expected the image to be embedded")
+	}
+
+	opf := files["OEBPS/content.opf"]
+	for _, expected := range []string{
+		"<dc:identifier id=\"book-id\">urn:uuid:page-1</dc:identifier>",
+		"<dc:title>Say &#34;hello&#34;</dc:title>",
+		"<dc:language>ja</dc:language>",
+		"<meta property=\"dcterms:modified\">2024-01-02T15:30:00Z</meta>",
+		"href=\"images/diagram-0c1d2e3f.png\" media-type=\"image/png\"",
+		"<itemref idref=\"chapter-2\"/>",
+	} {
+		if !strings.Contains(opf, expected) {
+			t.Errorf("Expected the package document to contain %q, got %s", expected, opf)
+		}
+	}
+
+	chapter := files["OEBPS/chapter-001.xhtml"]
+	for _, expected := range []string{
+		"<h1 id=\"section-1\">Intro</h1>",
+		"<img src=\"images/diagram-0c1d2e3f.png\" alt=\"\"/>",
+		"<hr/>",
+	} {
+		if !strings.Contains(chapter, expected) {
+			t.Errorf("Expected the chapter to contain %q, got %s", expected, chapter)
+		}
+	}
+
+	nav := files["OEBPS/nav.xhtml"]
+	expectedNav := "<li><a href=\"chapter-001.xhtml\">Say &#34;hello&#34;</a>\n<ol>\n" +
+		"<li><a href=\"chapter-001.xhtml#section-1\">Intro</a>\n<ol>\n" +
+		"<li><a href=\"chapter-001.xhtml#section-2\">Details</a></li>\n</ol>\n</li>\n</ol>\n</li>\n" +
+		"<li><a href=\"chapter-002.xhtml\">Child</a>"
+	if !strings.Contains(nav, expectedNav) {
+		t.Errorf("Expected the navigation to contain %q, got %s", expectedNav, nav)
+	}
+
+	// The documents are not changed by embedding images
+	if root.Nodes[2].URL != "https://example.com/diagram.png" {
+		t.Errorf("Expected the image URL to be kept, got %q", root.Nodes[2].URL)
+	}
+}
+
+func TestWriteEPUBDownloadError(t *testing.T) {
+	doc := (&Converter{opts: Options{Format: FormatHTML}}).mustRender(t, PageInfo{ID: "page-1"}, []BlockWithIndent{
+		{Block: createImageBlock("image-1", "https://example.com/a.png"), Indent: 0},
+	})
+
+	err := WriteEPUB(context.Background(), io.Discard, []Document{doc}, EPUBOptions{
+		Download: func(ctx context.Context, url string) ([]byte, error) {
+			return nil, errors.New("not found")
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "https://example.com/a.png") {
+		t.Errorf("Expected a download error, got %v", err)
+	}
+}
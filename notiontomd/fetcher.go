@@ -0,0 +1,169 @@
+package notiontomd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// BlockWithIndent holds a block and its indentation level
+type BlockWithIndent struct {
+	Block  notionapi.Block
+	Indent int
+	// Truncated is set when the block has children that were not fetched
+	// because they are nested deeper than FetchOptions.MaxDepth
+	Truncated bool
+}
+
+// BlockFetcher is an interface for fetching blocks from Notion API
+type BlockFetcher interface {
+	GetChildren(ctx context.Context, blockID notionapi.BlockID, pagination *notionapi.Pagination) (*notionapi.GetChildrenResponse, error)
+}
+
+// PageFetcher is an interface for fetching page objects from Notion API
+type PageFetcher interface {
+	Get(ctx context.Context, pageID notionapi.PageID) (*notionapi.Page, error)
+}
+
+// DefaultMaxDepth is the nesting depth fetched when nothing else is configured
+const DefaultMaxDepth = 10
+
+// FetchOptions controls how deep the block tree is fetched
+type FetchOptions struct {
+	// MaxDepth is the deepest indent level that is fetched. Zero means unlimited.
+	MaxDepth int
+	// Truncate omits blocks nested deeper than MaxDepth instead of failing
+	Truncate bool
+	// OnTruncate is called for each block whose children were omitted
+	OnTruncate func(blockID notionapi.BlockID, depth int)
+}
+
+// DefaultFetchOptions returns the options used when nothing is configured
+func DefaultFetchOptions() FetchOptions {
+	return FetchOptions{MaxDepth: DefaultMaxDepth}
+}
+
+// FetchAllBlocks fetches all blocks recursively starting from the given block ID
+func FetchAllBlocks(ctx context.Context, fetcher BlockFetcher, blockID notionapi.BlockID, opts FetchOptions) ([]BlockWithIndent, error) {
+	return fetchAllBlocksRecursive(ctx, fetcher, blockID, 0, opts)
+}
+
+// fetchAllBlocksRecursive recursively fetches blocks with depth tracking
+func fetchAllBlocksRecursive(ctx context.Context, fetcher BlockFetcher, blockID notionapi.BlockID, depth int, opts FetchOptions) ([]BlockWithIndent, error) {
+	limited := opts.MaxDepth > 0
+	if limited && depth > opts.MaxDepth {
+		return nil, fmt.Errorf("maximum recursion depth (%d) exceeded", opts.MaxDepth)
+	}
+
+	// Fetch children blocks with pagination
+	blocks, err := fetchBlockChildren(ctx, fetcher, blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []BlockWithIndent
+	for _, block := range blocks {
+		// Omit the children of blocks at the depth limit when truncating
+		truncated := block.GetHasChildren() && limited && opts.Truncate && depth >= opts.MaxDepth
+		if truncated && opts.OnTruncate != nil {
+			opts.OnTruncate(block.GetID(), depth+1)
+		}
+
+		// Add current block
+		result = append(result, BlockWithIndent{
+			Block:     block,
+			Indent:    depth,
+			Truncated: truncated,
+		})
+
+		// Recursively fetch children if HasChildren is true. The children of
+		// child pages and databases belong to those pages, not to this one.
+		if block.GetHasChildren() && !truncated && !isChildPage(block) {
+			children, err := fetchAllBlocksRecursive(ctx, fetcher, block.GetID(), depth+1, opts)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, children...)
+		}
+	}
+
+	return result, nil
+}
+
+// isChildPage reports whether the block is a child page or child database
+func isChildPage(block notionapi.Block) bool {
+	switch block.GetType() {
+	case notionapi.BlockTypeChildPage, notionapi.BlockTypeChildDatabase:
+		return true
+	}
+	return false
+}
+
+// fetchBlockChildren fetches children of a block with pagination support
+func fetchBlockChildren(ctx context.Context, fetcher BlockFetcher, blockID notionapi.BlockID) ([]notionapi.Block, error) {
+	var allBlocks []notionapi.Block
+	pagination := &notionapi.Pagination{}
+
+	for {
+		resp, err := fetcher.GetChildren(ctx, blockID, pagination)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get children for block %s: %w", blockID, err)
+		}
+
+		allBlocks = append(allBlocks, resp.Results...)
+
+		if !resp.HasMore {
+			break
+		}
+		pagination.StartCursor = notionapi.Cursor(resp.NextCursor)
+	}
+
+	return allBlocks, nil
+}
+
+// FetchPageInfo fetches page metadata from Notion API
+func FetchPageInfo(ctx context.Context, fetcher PageFetcher, pageID notionapi.PageID) (PageInfo, error) {
+	page, err := fetcher.Get(ctx, pageID)
+	if err != nil {
+		return PageInfo{}, fmt.Errorf("failed to get page info: %w", err)
+	}
+
+	return PageInfoFromPage(page), nil
+}
+
+// PageInfoFromPage extracts page metadata from a page object
+func PageInfoFromPage(page *notionapi.Page) PageInfo {
+	// Extract title from properties
+	var title string
+	for _, prop := range page.Properties {
+		if titleProp, ok := prop.(*notionapi.TitleProperty); ok {
+			var titleBuilder strings.Builder
+			for _, rt := range titleProp.Title {
+				titleBuilder.WriteString(rt.PlainText)
+			}
+			title = titleBuilder.String()
+			break
+		}
+	}
+
+	var icon string
+	if page.Icon != nil {
+		if page.Icon.Emoji != nil {
+			icon = string(*page.Icon.Emoji)
+		} else {
+			icon = page.Icon.GetURL()
+		}
+	}
+
+	return PageInfo{
+		ID:             notionapi.BlockID(page.ID),
+		Title:          title,
+		URL:            page.URL,
+		CreatedTime:    page.CreatedTime,
+		LastEditedTime: page.LastEditedTime,
+		Icon:           icon,
+		Properties:     NewProperties(page.Properties),
+	}
+}
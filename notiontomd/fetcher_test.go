@@ -1,4 +1,4 @@
-package main
+package notiontomd
 
 import (
 	"context"
@@ -201,7 +201,7 @@ func TestFetchAllBlocksRecursiveNoChildren(t *testing.T) {
 		},
 	}
 
-	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0)
+	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0, DefaultFetchOptions())
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -241,7 +241,7 @@ func TestFetchAllBlocksRecursiveWithChildren(t *testing.T) {
 		},
 	}
 
-	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0)
+	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0, DefaultFetchOptions())
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -281,7 +281,7 @@ func TestFetchAllBlocksRecursiveDeepNesting(t *testing.T) {
 		},
 	}
 
-	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0)
+	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0, DefaultFetchOptions())
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -312,7 +312,7 @@ func TestFetchAllBlocksRecursiveMaxDepthExceeded(t *testing.T) {
 	}
 
 	// Depth 11 exceeds max depth of 10
-	_, err := fetchAllBlocksRecursive(ctx, mock, blockID, 11)
+	_, err := fetchAllBlocksRecursive(ctx, mock, blockID, 11, DefaultFetchOptions())
 	if err == nil {
 		t.Fatal("Expected error for max depth exceeded, got nil")
 	}
@@ -330,7 +330,7 @@ func TestFetchAllBlocksRecursiveError(t *testing.T) {
 		err: errors.New("API error"),
 	}
 
-	_, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0)
+	_, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0, DefaultFetchOptions())
 	if err == nil {
 		t.Fatal("Expected error, got nil")
 	}
@@ -353,7 +353,7 @@ func TestFetchAllBlocks(t *testing.T) {
 		},
 	}
 
-	result, err := fetchAllBlocks(ctx, mock, blockID)
+	result, err := FetchAllBlocks(ctx, mock, blockID, DefaultFetchOptions())
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -395,7 +395,7 @@ func TestFetchAllBlocksRecursiveNestedBulletedList(t *testing.T) {
 		},
 	}
 
-	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0)
+	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0, DefaultFetchOptions())
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -460,7 +460,7 @@ func TestFetchAllBlocksRecursiveNestedNumberedList(t *testing.T) {
 		},
 	}
 
-	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0)
+	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0, DefaultFetchOptions())
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -519,7 +519,7 @@ func TestFetchAllBlocksRecursiveMixedBlockTypes(t *testing.T) {
 		},
 	}
 
-	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0)
+	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, 0, DefaultFetchOptions())
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -547,3 +547,146 @@ func TestFetchAllBlocksRecursiveMixedBlockTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestFetchAllBlocksRecursiveCustomMaxDepthExceeded(t *testing.T) {
+	ctx := context.Background()
+	blockID := notionapi.BlockID("test-block-id")
+
+	mock := &mockBlockFetcher{
+		responses: []*notionapi.GetChildrenResponse{
+			{
+				Results: []notionapi.Block{createParagraphBlock("parent", true)},
+				HasMore: false,
+			},
+			{
+				Results: []notionapi.Block{createParagraphBlock("child", true)},
+				HasMore: false,
+			},
+		},
+	}
+
+	_, err := FetchAllBlocks(ctx, mock, blockID, FetchOptions{MaxDepth: 1})
+	if err == nil {
+		t.Fatal("Expected error for max depth exceeded, got nil")
+	}
+
+	if err.Error() != "maximum recursion depth (1) exceeded" {
+		t.Errorf("Expected max depth error, got: %v", err)
+	}
+}
+
+func TestFetchAllBlocksRecursiveUnlimitedDepth(t *testing.T) {
+	ctx := context.Background()
+	blockID := notionapi.BlockID("test-block-id")
+
+	// Start deeper than the default limit; MaxDepth 0 must not fail
+	mock := &mockBlockFetcher{
+		responses: []*notionapi.GetChildrenResponse{
+			{
+				Results: []notionapi.Block{createParagraphBlock("block", false)},
+				HasMore: false,
+			},
+		},
+	}
+
+	result, err := fetchAllBlocksRecursive(ctx, mock, blockID, DefaultMaxDepth+5, FetchOptions{MaxDepth: 0})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result) != 1 {
+		t.Errorf("Expected 1 block, got %d", len(result))
+	}
+}
+
+func TestFetchAllBlocksRecursiveTruncate(t *testing.T) {
+	ctx := context.Background()
+	blockID := notionapi.BlockID("test-block-id")
+
+	// parent -> child -> grandchild, truncated at depth 1
+	mock := &mockBlockFetcher{
+		responses: []*notionapi.GetChildrenResponse{
+			{
+				Results: []notionapi.Block{createParagraphBlock("parent", true)},
+				HasMore: false,
+			},
+			{
+				Results: []notionapi.Block{createParagraphBlock("child", true)},
+				HasMore: false,
+			},
+			{
+				Results: []notionapi.Block{createParagraphBlock("grandchild", false)},
+				HasMore: false,
+			},
+		},
+	}
+
+	var truncatedIDs []notionapi.BlockID
+	opts := FetchOptions{
+		MaxDepth: 1,
+		Truncate: true,
+		OnTruncate: func(blockID notionapi.BlockID, depth int) {
+			truncatedIDs = append(truncatedIDs, blockID)
+		},
+	}
+
+	result, err := FetchAllBlocks(ctx, mock, blockID, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 blocks, got %d", len(result))
+	}
+
+	if result[0].Truncated {
+		t.Errorf("Expected parent not to be truncated")
+	}
+	if !result[1].Truncated {
+		t.Errorf("Expected child to be truncated")
+	}
+
+	if len(truncatedIDs) != 1 || truncatedIDs[0] != "child" {
+		t.Errorf("Expected OnTruncate for [child], got %v", truncatedIDs)
+	}
+
+	if mock.callCount != 2 {
+		t.Errorf("Expected 2 API calls, got %d", mock.callCount)
+	}
+}
+
+func TestFetchAllBlocksSkipsChildPageContent(t *testing.T) {
+	ctx := context.Background()
+	blockID := notionapi.BlockID("test-block-id")
+
+	childPage := &notionapi.ChildPageBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object:      "block",
+			ID:          "child-page",
+			Type:        notionapi.BlockTypeChildPage,
+			HasChildren: true,
+		},
+	}
+
+	mock := &mockBlockFetcher{
+		responses: []*notionapi.GetChildrenResponse{
+			{
+				Results: []notionapi.Block{childPage, createParagraphBlock("block-1", false)},
+				HasMore: false,
+			},
+		},
+	}
+
+	result, err := FetchAllBlocks(ctx, mock, blockID, DefaultFetchOptions())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Errorf("Expected 2 blocks, got %d", len(result))
+	}
+
+	if mock.callCount != 1 {
+		t.Errorf("Expected 1 API call, got %d", mock.callCount)
+	}
+}
@@ -0,0 +1,188 @@
+package notiontomd
+
+import (
+	"encoding/xml"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// Hatena writes Markdown for Hatena Blog with the front matter of blogsync
+var Hatena = &Preset{
+	Name:        "hatena",
+	FrontMatter: hatenaFrontMatter,
+	Register:    registerHatena,
+	Prepare:     hatenaFootnotes,
+}
+
+// HatenaAtomPub writes a Hatena Blog AtomPub entry that can be posted to
+// the collection URI of a blog
+var HatenaAtomPub = &Preset{
+	Name:        "hatena-atompub",
+	FrontMatter: hatenaFrontMatter,
+	Register:    registerHatena,
+	Prepare:     hatenaFootnotes,
+	Document:    HatenaEntry,
+}
+
+// hatenaFrontMatter writes the front matter of blogsync
+func hatenaFrontMatter(meta SiteMeta) string {
+	var result strings.Builder
+
+	result.WriteString("---\n")
+	result.WriteString("Title: " + quoteString(meta.Title) + "\n")
+	if len(meta.Tags) > 0 {
+		result.WriteString("Category:\n")
+		for _, tag := range meta.Tags {
+			result.WriteString("- " + quoteString(tag) + "\n")
+		}
+	}
+	result.WriteString("Date: " + meta.Date.Format(time.RFC3339) + "\n")
+	if meta.Slug != "" {
+		result.WriteString("CustomPath: " + quoteString(meta.Slug) + "\n")
+	}
+	if meta.Draft {
+		result.WriteString("Draft: true\n")
+	}
+	result.WriteString("---\n\n")
+
+	return result.String()
+}
+
+// registerHatena adds the Hatena notations for the table of contents and
+// embedded links
+func registerHatena(r *Renderer) {
+	r.Register(notionapi.BlockTypeTableOfContents, func(r *Renderer, node *Node, depth int) string {
+		return "[:contents]\n\n"
+	})
+	embed := func(r *Renderer, node *Node, depth int) string {
+		if node.URL == "" {
+			return ""
+		}
+		return "[" + node.URL + ":embed]\n\n"
+	}
+	r.Register(notionapi.BlockTypeBookmark, embed)
+	r.Register(notionapi.BlockTypeEmbed, embed)
+	r.Register(notionapi.BlockTypeVideo, embed)
+}
+
+// footnoteReference matches Markdown footnote references such as [^1]
+var footnoteReference = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+
+// footnoteDefinition matches paragraphs defining a footnote, such as "[^1]: Text"
+var footnoteDefinition = regexp.MustCompile(`^\[\^([^\]\s]+)\]:\s*`)
+
+// hatenaFootnotes turns Markdown footnotes written in Notion into Hatena
+// footnotes: each [^label] reference is replaced by ((text)) and the
+// "[^label]: text" paragraphs are removed. The nodes are not modified.
+func hatenaFootnotes(nodes []*Node) []*Node {
+	footnotes := make(map[string]string)
+	collectFootnotes(nodes, footnotes)
+	if len(footnotes) == 0 {
+		return nodes
+	}
+	return replaceFootnotes(nodes, footnotes)
+}
+
+// collectFootnotes stores the text of footnote definitions by label
+func collectFootnotes(nodes []*Node, footnotes map[string]string) {
+	for _, node := range nodes {
+		if label, text, ok := footnote(node); ok {
+			footnotes[label] = text
+		}
+		collectFootnotes(node.Children, footnotes)
+	}
+}
+
+// footnote returns the label and text of a footnote definition paragraph
+func footnote(node *Node) (label, text string, ok bool) {
+	if node.Type != notionapi.BlockTypeParagraph {
+		return "", "", false
+	}
+	plain := PlainText(node.Text)
+	match := footnoteDefinition.FindStringSubmatch(plain)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], strings.TrimSpace(plain[len(match[0]):]), true
+}
+
+// replaceFootnotes returns copies of the nodes without footnote definitions
+// and with references replaced
+func replaceFootnotes(nodes []*Node, footnotes map[string]string) []*Node {
+	result := make([]*Node, 0, len(nodes))
+	for _, node := range nodes {
+		if _, _, ok := footnote(node); ok {
+			continue
+		}
+		replaced := *node
+		replaced.Text = replaceFootnoteSpans(node.Text, footnotes)
+		replaced.Caption = replaceFootnoteSpans(node.Caption, footnotes)
+		if node.Cells != nil {
+			replaced.Cells = make([][]Span, len(node.Cells))
+			for i, cell := range node.Cells {
+				replaced.Cells[i] = replaceFootnoteSpans(cell, footnotes)
+			}
+		}
+		if node.Children != nil {
+			replaced.Children = replaceFootnotes(node.Children, footnotes)
+		}
+		result = append(result, &replaced)
+	}
+	return result
+}
+
+// replaceFootnoteSpans replaces footnote references outside of code
+func replaceFootnoteSpans(spans []Span, footnotes map[string]string) []Span {
+	if spans == nil {
+		return nil
+	}
+	result := make([]Span, len(spans))
+	for i, span := range spans {
+		if span.Type == "text" && !span.Code {
+			span.Text = footnoteReference.ReplaceAllStringFunc(span.Text, func(reference string) string {
+				label := footnoteReference.FindStringSubmatch(reference)[1]
+				if text, ok := footnotes[label]; ok {
+					return "((" + text + "))"
+				}
+				return reference
+			})
+		}
+		result[i] = span
+	}
+	return result
+}
+
+// HatenaEntry wraps a Markdown body into a Hatena Blog AtomPub entry
+func HatenaEntry(meta SiteMeta, body string) string {
+	var result strings.Builder
+
+	result.WriteString(xml.Header)
+	result.WriteString(`<entry xmlns="http://www.w3.org/2005/Atom" xmlns:app="http://www.w3.org/2007/app" xmlns:hatenablog="http://www.hatena.ne.jp/info/xmlns#hatenablog">` + "\n")
+	result.WriteString("  <title>" + xmlEscape(meta.Title) + "</title>\n")
+	result.WriteString("  <updated>" + meta.Date.Format(time.RFC3339) + "</updated>\n")
+	for _, tag := range meta.Tags {
+		result.WriteString(`  <category term="` + xmlEscape(tag) + `" />` + "\n")
+	}
+	result.WriteString(`  <content type="text/x-markdown">` + xmlEscape(body) + "</content>\n")
+	if meta.Slug != "" {
+		result.WriteString("  <hatenablog:custom-url>" + xmlEscape(meta.Slug) + "</hatenablog:custom-url>\n")
+	}
+	draft := "no"
+	if meta.Draft {
+		draft = "yes"
+	}
+	result.WriteString("  <app:control>\n    <app:draft>" + draft + "</app:draft>\n  </app:control>\n")
+	result.WriteString("</entry>\n")
+
+	return result.String()
+}
+
+// xmlEscape escapes text for XML character data and attribute values.
+// Unlike xml.EscapeText, it keeps newlines so the body stays readable.
+func xmlEscape(s string) string {
+	return html.EscapeString(s)
+}
@@ -0,0 +1,107 @@
+package notiontomd
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// Helper function to create a paragraph block with the given text
+func createTextParagraphBlock(text string) notionapi.Block {
+	return &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			Type:   notionapi.BlockTypeParagraph,
+		},
+		Paragraph: notionapi.Paragraph{
+			RichText: []notionapi.RichText{{Type: "text", PlainText: text}},
+		},
+	}
+}
+
+func TestHatenaBlocks(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: &notionapi.TableOfContentsBlock{
+			BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeTableOfContents},
+		}, Indent: 0},
+		{Block: createTextParagraphBlock("Go is fast[^1] and simple[^2]."), Indent: 0},
+		{Block: createBookmarkBlock("https://go.dev/"), Indent: 0},
+		{Block: createTextParagraphBlock("[^1]: Compiled to native code"), Indent: 0},
+	}
+
+	doc := (&Converter{opts: Options{Preset: Hatena, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+	expected := "[:contents]\n\n" +
+		"Go is fast((Compiled to native code)) and simple[^2].\n\n" +
+		"[https://go.dev/:embed]\n\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+
+	// The tree of the document keeps the footnote definition
+	if len(doc.Nodes) != 4 || PlainText(doc.Nodes[1].Text) != "Go is fast[^1] and simple[^2]." {
+		t.Errorf("Expected the document nodes to be unchanged, got %+v", doc.Nodes)
+	}
+}
+
+func TestHatenaFrontMatter(t *testing.T) {
+	meta := NewSiteMeta(samplePageInfo(), map[string]string{FieldDate: "Published", FieldDraft: "Status"})
+
+	result := Hatena.FrontMatter(meta)
+
+	expected := "---\n" +
+		"Title: \"Say \\\"hello\\\"\"\n" +
+		"Category:\n- \"go\"\n- \"notion\"\n" +
+		"Date: 2024-03-01T00:00:00Z\n" +
+		"CustomPath: \"say-hello\"\n" +
+		"Draft: true\n" +
+		"---\n\n"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestHatenaAtomPub(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createTextParagraphBlock("a < b & c"), Indent: 0},
+	}
+
+	doc := (&Converter{opts: Options{Preset: HatenaAtomPub}}).mustRender(t, samplePageInfo(), blocks)
+
+	if doc.FrontMatter != "" {
+		t.Errorf("Expected no front matter, got %q", doc.FrontMatter)
+	}
+
+	var entry struct {
+		Title      string `xml:"title"`
+		Content    string `xml:"content"`
+		Categories []struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+		CustomURL string `xml:"http://www.hatena.ne.jp/info/xmlns#hatenablog custom-url"`
+		Draft     string `xml:"http://www.w3.org/2007/app control>draft"`
+	}
+	if err := xml.Unmarshal([]byte(doc.Content), &entry); err != nil {
+		t.Fatalf("Expected valid XML, got %v\n%s", err, doc.Content)
+	}
+	if entry.Title != `Say "hello"` {
+		t.Errorf("Expected %q, got %q", `Say "hello"`, entry.Title)
+	}
+	if entry.Content != "a < b & c\n\n" {
+		t.Errorf("Expected %q, got %q", "a < b & c\n\n", entry.Content)
+	}
+	if len(entry.Categories) != 2 || entry.Categories[0].Term != "go" {
+		t.Errorf("Expected the tags as categories, got %+v", entry.Categories)
+	}
+	if entry.CustomURL != "say-hello" {
+		t.Errorf("Expected %q, got %q", "say-hello", entry.CustomURL)
+	}
+	if entry.Draft != "no" {
+		t.Errorf("Expected %q, got %q", "no", entry.Draft)
+	}
+	if !strings.HasPrefix(doc.Content, xml.Header) {
+		t.Errorf("Expected an XML declaration, got %q", doc.Content)
+	}
+}
@@ -0,0 +1,343 @@
+package notiontomd
+
+import (
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// htmlStyle is the minimal CSS embedded by HTMLDocument when requested
+const htmlStyle = `body { max-width: 46em; margin: 2em auto; padding: 0 1em; font-family: -apple-system, "Segoe UI", "Hiragino Sans", sans-serif; line-height: 1.6; color: #37352f; }
+pre { padding: 1em; overflow-x: auto; background: #f7f6f3; }
+code { font-family: SFMono-Regular, Consolas, monospace; font-size: 0.9em; }
+blockquote { margin: 0; padding-left: 1em; border-left: 3px solid #37352f; }
+aside.callout { display: flex; gap: 0.5em; padding: 1em; border-radius: 4px; background: #f1f1ef; }
+table { border-collapse: collapse; }
+th, td { padding: 0.3em 0.6em; border: 1px solid #e9e9e7; text-align: left; vertical-align: top; }
+figure { margin: 1em 0; }
+img { max-width: 100%; }
+figcaption { color: #787774; font-size: 0.9em; }
+ul.todo { list-style: none; padding-left: 0.5em; }
+`
+
+// ConvertHTML converts blocks with indentation to an HTML fragment
+func ConvertHTML(blocks []BlockWithIndent) string {
+	return RenderHTML(NewNodes(blocks))
+}
+
+// RenderHTML converts a block tree to an HTML fragment
+func RenderHTML(nodes []*Node) string {
+	var result strings.Builder
+	writeHTMLNodes(&result, nodes)
+	return result.String()
+}
+
+// HTMLDocument wraps an HTML fragment in a standalone document whose head
+// carries the page metadata. style embeds a minimal stylesheet.
+func HTMLDocument(info PageInfo, body string, style bool) string {
+	var result strings.Builder
+
+	result.WriteString("<!DOCTYPE html>\n")
+	result.WriteString("<html>\n<head>\n")
+	result.WriteString("<meta charset=\"utf-8\">\n")
+	result.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	result.WriteString("<title>" + html.EscapeString(info.Title) + "</title>\n")
+	if info.URL != "" {
+		result.WriteString("<link rel=\"canonical\" href=\"" + html.EscapeString(info.URL) + "\">\n")
+	}
+	result.WriteString("<meta name=\"created\" content=\"" + info.CreatedTime.Format(time.RFC3339) + "\">\n")
+	result.WriteString("<meta name=\"updated\" content=\"" + info.LastEditedTime.Format(time.RFC3339) + "\">\n")
+	if style {
+		result.WriteString("<style>\n" + htmlStyle + "</style>\n")
+	}
+	result.WriteString("</head>\n<body>\n")
+	result.WriteString(body)
+	result.WriteString("</body>\n</html>\n")
+
+	return result.String()
+}
+
+// writeHTMLNodes writes sibling blocks, grouping consecutive list items into
+// a single list element
+func writeHTMLNodes(w *strings.Builder, nodes []*Node) {
+	for i := 0; i < len(nodes); {
+		listTag, class := htmlListTag(nodes[i].Type)
+		if listTag == "" {
+			writeHTMLNode(w, nodes[i])
+			i++
+			continue
+		}
+
+		if class != "" {
+			w.WriteString("<" + listTag + " class=\"" + class + "\">\n")
+		} else {
+			w.WriteString("<" + listTag + ">\n")
+		}
+		blockType := nodes[i].Type
+		for ; i < len(nodes) && nodes[i].Type == blockType; i++ {
+			writeHTMLListItem(w, nodes[i])
+		}
+		w.WriteString("</" + listTag + ">\n")
+	}
+}
+
+// htmlListTag returns the list element and its class for list item blocks,
+// or an empty tag for other blocks
+func htmlListTag(blockType notionapi.BlockType) (string, string) {
+	switch blockType {
+	case notionapi.BlockTypeBulletedListItem:
+		return "ul", ""
+	case notionapi.BlockTypeNumberedListItem:
+		return "ol", ""
+	case notionapi.BlockTypeToDo:
+		return "ul", "todo"
+	}
+	return "", ""
+}
+
+// writeHTMLListItem writes a list item with its nested blocks
+func writeHTMLListItem(w *strings.Builder, node *Node) {
+	w.WriteString("<li>")
+	if node.Type == notionapi.BlockTypeToDo {
+		if node.Checked {
+			w.WriteString("<input type=\"checkbox\" checked disabled> ")
+		} else {
+			w.WriteString("<input type=\"checkbox\" disabled> ")
+		}
+	}
+	w.WriteString(FormatRichTextHTML(node.Text))
+	if len(node.Children) > 0 || node.Truncated {
+		w.WriteString("\n")
+		writeHTMLChildren(w, node)
+	}
+	w.WriteString("</li>\n")
+}
+
+// writeHTMLChildren writes the nested blocks of a node and the truncation marker
+func writeHTMLChildren(w *strings.Builder, node *Node) {
+	writeHTMLNodes(w, node.Children)
+	if node.Truncated {
+		w.WriteString("<p class=\"truncated\"><em>(truncated: nested blocks omitted)</em></p>\n")
+	}
+}
+
+// writeHTMLNode writes a single block that is not a list item
+func writeHTMLNode(w *strings.Builder, node *Node) {
+	switch node.Type {
+	case notionapi.BlockTypeHeading1:
+		writeHTMLHeading(w, "h1", node)
+		return
+	case notionapi.BlockTypeHeading2:
+		writeHTMLHeading(w, "h2", node)
+		return
+	case notionapi.BlockTypeHeading3:
+		writeHTMLHeading(w, "h3", node)
+		return
+	case notionapi.BlockTypeParagraph:
+		if text := FormatRichTextHTML(node.Text); text != "" {
+			w.WriteString("<p>" + text + "</p>\n")
+		}
+	case notionapi.BlockTypeCode:
+		writeHTMLCode(w, node)
+	case notionapi.BlockTypeToggle:
+		w.WriteString("<details>\n<summary>" + FormatRichTextHTML(node.Text) + "</summary>\n")
+		writeHTMLChildren(w, node)
+		w.WriteString("</details>\n")
+		return
+	case notionapi.BlockTypeQuote:
+		w.WriteString("<blockquote>\n<p>" + FormatRichTextHTML(node.Text) + "</p>\n")
+		writeHTMLChildren(w, node)
+		w.WriteString("</blockquote>\n")
+		return
+	case notionapi.BlockTypeCallout:
+		w.WriteString("<aside class=\"callout\">\n")
+		if node.Icon != "" && !strings.Contains(node.Icon, "://") {
+			w.WriteString("<span class=\"callout-icon\">" + html.EscapeString(node.Icon) + "</span>\n")
+		}
+		w.WriteString("<div>\n<p>" + FormatRichTextHTML(node.Text) + "</p>\n")
+		writeHTMLChildren(w, node)
+		w.WriteString("</div>\n</aside>\n")
+		return
+	case notionapi.BlockTypeDivider:
+		w.WriteString("<hr>\n")
+	case notionapi.BlockTypeImage:
+		writeHTMLImage(w, node)
+	case notionapi.BlockTypeTableBlock:
+		writeHTMLTable(w, node)
+		return
+	case notionapi.BlockTypeBookmark:
+		writeHTMLLink(w, "bookmark", node)
+	case notionapi.BlockTypeEmbed:
+		writeHTMLLink(w, "embed", node)
+	case notionapi.BlockTypeEquation:
+		w.WriteString("<div class=\"equation\">\\[" + html.EscapeString(node.Expression) + "\\]</div>\n")
+	case notionapi.BlockTypeColumnList:
+		w.WriteString("<div class=\"columns\">\n")
+		writeHTMLChildren(w, node)
+		w.WriteString("</div>\n")
+		return
+	case notionapi.BlockTypeColumn:
+		w.WriteString("<div class=\"column\">\n")
+		writeHTMLChildren(w, node)
+		w.WriteString("</div>\n")
+		return
+	}
+
+	// Blocks such as paragraphs can have indented children in Notion
+	if len(node.Children) > 0 || node.Truncated {
+		w.WriteString("<div class=\"indented\">\n")
+		writeHTMLChildren(w, node)
+		w.WriteString("</div>\n")
+	}
+}
+
+// writeHTMLHeading writes a heading. Toggleable headings become details elements.
+func writeHTMLHeading(w *strings.Builder, tag string, node *Node) {
+	element := "<" + tag + ">" + FormatRichTextHTML(node.Text) + "</" + tag + ">"
+
+	if node.Toggleable {
+		w.WriteString("<details>\n<summary>" + element + "</summary>\n")
+		writeHTMLChildren(w, node)
+		w.WriteString("</details>\n")
+		return
+	}
+
+	w.WriteString(element + "\n")
+	writeHTMLChildren(w, node)
+}
+
+// writeHTMLCode writes a code block with the language as a class, following
+// the convention of syntax highlighters such as highlight.js and Prism
+func writeHTMLCode(w *strings.Builder, node *Node) {
+	caption := FormatRichTextHTML(node.Caption)
+	if caption != "" {
+		w.WriteString("<figure>\n")
+	}
+	w.WriteString("<pre><code")
+	if node.Language != "" {
+		w.WriteString(" class=\"language-" + html.EscapeString(node.Language) + "\"")
+	}
+	w.WriteString(">" + html.EscapeString(PlainText(node.Text)) + "</code></pre>\n")
+	if caption != "" {
+		w.WriteString("<figcaption>" + caption + "</figcaption>\n</figure>\n")
+	}
+}
+
+// writeHTMLImage writes an image with its caption as a figure
+func writeHTMLImage(w *strings.Builder, node *Node) {
+	alt := html.EscapeString(PlainText(node.Caption))
+	w.WriteString("<figure>\n")
+	w.WriteString("<img src=\"" + html.EscapeString(node.URL) + "\" alt=\"" + alt + "\">\n")
+	if caption := FormatRichTextHTML(node.Caption); caption != "" {
+		w.WriteString("<figcaption>" + caption + "</figcaption>\n")
+	}
+	w.WriteString("</figure>\n")
+}
+
+// writeHTMLTable writes a table from its row blocks. The first row becomes
+// the header when the table has a column header.
+func writeHTMLTable(w *strings.Builder, table *Node) {
+	var rows [][][]Span
+	for _, row := range table.Children {
+		if row.Type == notionapi.BlockTypeTableRowBlock {
+			rows = append(rows, row.Cells)
+		}
+	}
+
+	w.WriteString("<table>\n")
+	if table.ColumnHeader && len(rows) > 0 {
+		w.WriteString("<thead>\n")
+		writeHTMLTableRow(w, rows[0], true, false)
+		w.WriteString("</thead>\n")
+		rows = rows[1:]
+	}
+	if len(rows) > 0 {
+		w.WriteString("<tbody>\n")
+		for _, row := range rows {
+			writeHTMLTableRow(w, row, false, table.RowHeader)
+		}
+		w.WriteString("</tbody>\n")
+	}
+	w.WriteString("</table>\n")
+}
+
+// writeHTMLTableRow writes a table row. Header cells use th.
+func writeHTMLTableRow(w *strings.Builder, row [][]Span, header bool, rowHeader bool) {
+	w.WriteString("<tr>")
+	for i, cell := range row {
+		tag := "td"
+		if header || (i == 0 && rowHeader) {
+			tag = "th"
+		}
+		w.WriteString("<" + tag + ">" + FormatRichTextHTML(cell) + "</" + tag + ">")
+	}
+	w.WriteString("</tr>\n")
+}
+
+// writeHTMLLink writes a bookmark or embed as a link, using the caption as its text when set
+func writeHTMLLink(w *strings.Builder, class string, node *Node) {
+	text := FormatRichTextHTML(node.Caption)
+	if text == "" {
+		text = html.EscapeString(node.URL)
+	}
+	if !safeLinkURL(node.URL) {
+		w.WriteString("<p class=\"" + class + "\">" + text + "</p>\n")
+		return
+	}
+	w.WriteString("<p class=\"" + class + "\"><a href=\"" + html.EscapeString(node.URL) + "\">" + text + "</a></p>\n")
+}
+
+// safeLinkURL reports whether a URL may be written as a link target: http,
+// https and mailto URLs and relative URLs. Other schemes, e.g. javascript:,
+// could run code when the link is followed.
+func safeLinkURL(rawURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// FormatRichTextHTML converts rich text to escaped HTML with annotations.
+// Line breaks become br elements. Links with schemes other than http, https
+// and mailto are written as plain text.
+func FormatRichTextHTML(spans []Span) string {
+	var result strings.Builder
+
+	for _, span := range spans {
+		text := html.EscapeString(span.Text)
+		text = strings.ReplaceAll(text, "\n", "<br>")
+
+		// Apply annotations in the same order as the Markdown renderer
+		if span.Code {
+			text = "<code>" + text + "</code>"
+		}
+		if span.Bold {
+			text = "<strong>" + text + "</strong>"
+		}
+		if span.Italic {
+			text = "<em>" + text + "</em>"
+		}
+		if span.Strikethrough {
+			text = "<s>" + text + "</s>"
+		}
+		if span.Underline {
+			text = "<u>" + text + "</u>"
+		}
+
+		if span.Href != "" && safeLinkURL(span.Href) {
+			text = "<a href=\"" + html.EscapeString(span.Href) + "\">" + text + "</a>"
+		}
+
+		result.WriteString(text)
+	}
+
+	return result.String()
+}
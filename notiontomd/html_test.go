@@ -0,0 +1,275 @@
+package notiontomd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestConvertHTMLNestedLists(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createBulletedListBlock("block-1", "Parent", true), Indent: 0},
+		{Block: createNumberedListBlock("block-2", "First", false), Indent: 1},
+		{Block: createNumberedListBlock("block-3", "Second", false), Indent: 1},
+		{Block: createBulletedListBlock("block-4", "Sibling", false), Indent: 0},
+		{Block: createParagraphBlock("block-5", false), Indent: 0},
+	}
+
+	result := ConvertHTML(blocks)
+	expected := "<ul>\n" +
+		"<li>Parent\n" +
+		"<ol>\n<li>First</li>\n<li>Second</li>\n</ol>\n" +
+		"</li>\n" +
+		"<li>Sibling</li>\n" +
+		"</ul>\n" +
+		"<p>Test block</p>\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertHTMLToggle(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{
+			Block: &notionapi.ToggleBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object:      "block",
+					Type:        notionapi.BlockTypeToggle,
+					HasChildren: true,
+				},
+				Toggle: notionapi.Toggle{
+					RichText: []notionapi.RichText{
+						{PlainText: "Details"},
+					},
+				},
+			},
+			Indent: 0,
+		},
+		{Block: createParagraphBlock("block-2", false), Indent: 1},
+	}
+
+	result := ConvertHTML(blocks)
+	expected := "<details>\n<summary>Details</summary>\n<p>Test block</p>\n</details>\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertHTMLTable(t *testing.T) {
+	row := func(cells ...string) BlockWithIndent {
+		var richTexts [][]notionapi.RichText
+		for _, cell := range cells {
+			richTexts = append(richTexts, []notionapi.RichText{{PlainText: cell}})
+		}
+		return BlockWithIndent{
+			Block: &notionapi.TableRowBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object: "block",
+					Type:   notionapi.BlockTypeTableRowBlock,
+				},
+				TableRow: notionapi.TableRow{Cells: richTexts},
+			},
+			Indent: 1,
+		}
+	}
+	blocks := []BlockWithIndent{
+		{
+			Block: &notionapi.TableBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object:      "block",
+					Type:        notionapi.BlockTypeTableBlock,
+					HasChildren: true,
+				},
+				Table: notionapi.Table{
+					TableWidth:      2,
+					HasColumnHeader: true,
+				},
+			},
+			Indent: 0,
+		},
+		row("Name", "Value"),
+		row("a < b", "1"),
+	}
+
+	result := ConvertHTML(blocks)
+	expected := "<table>\n" +
+		"<thead>\n<tr><th>Name</th><th>Value</th></tr>\n</thead>\n" +
+		"<tbody>\n<tr><td>a &lt; b</td><td>1</td></tr>\n</tbody>\n" +
+		"</table>\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertHTMLImage(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{
+			Block: &notionapi.ImageBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object: "block",
+					Type:   notionapi.BlockTypeImage,
+				},
+				Image: notionapi.Image{
+					Type:     notionapi.FileTypeExternal,
+					External: &notionapi.FileObject{URL: "https://example.com/a.png?x=1&y=2"},
+					Caption: []notionapi.RichText{
+						{PlainText: "Diagram"},
+					},
+				},
+			},
+			Indent: 0,
+		},
+	}
+
+	result := ConvertHTML(blocks)
+	expected := "<figure>\n" +
+		"<img src=\"https://example.com/a.png?x=1&amp;y=2\" alt=\"Diagram\">\n" +
+		"<figcaption>Diagram</figcaption>\n" +
+		"</figure>\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertHTMLCode(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{
+			Block: &notionapi.CodeBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object: "block",
+					Type:   notionapi.BlockTypeCode,
+				},
+				Code: notionapi.Code{
+					RichText: []notionapi.RichText{
+						{PlainText: "if a < b && c {\n}"},
+					},
+					Language: "go",
+				},
+			},
+			Indent: 0,
+		},
+	}
+
+	result := ConvertHTML(blocks)
+	expected := "<pre><code class=\"language-go\">if a &lt; b &amp;&amp; c {\n}</code></pre>\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertHTMLTruncated(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createBulletedListBlock("block-1", "Deep", true), Indent: 0, Truncated: true},
+	}
+
+	result := ConvertHTML(blocks)
+	expected := "<ul>\n<li>Deep\n<p class=\"truncated\"><em>(truncated: nested blocks omitted)</em></p>\n</li>\n</ul>\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestFormatRichTextHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []notionapi.RichText
+		expected string
+	}{
+		{
+			name:     "escaping",
+			input:    []notionapi.RichText{{PlainText: `<script>"x" & 'y'</script>`}},
+			expected: "&lt;script&gt;&#34;x&#34; &amp; &#39;y&#39;&lt;/script&gt;",
+		},
+		{
+			name: "annotations",
+			input: []notionapi.RichText{
+				{
+					PlainText:   "text",
+					Annotations: &notionapi.Annotations{Bold: true, Italic: true, Code: true},
+				},
+			},
+			expected: "<em><strong><code>text</code></strong></em>",
+		},
+		{
+			name:     "link",
+			input:    []notionapi.RichText{{PlainText: "link", Href: "https://example.com/?a=1&b=2"}},
+			expected: "<a href=\"https://example.com/?a=1&amp;b=2\">link</a>",
+		},
+		{
+			name:     "unsafe link",
+			input:    []notionapi.RichText{{PlainText: "click", Href: "JavaScript:alert(1)"}},
+			expected: "click",
+		},
+		{
+			name:     "relative link",
+			input:    []notionapi.RichText{{PlainText: "page", Href: "/cec15681"}},
+			expected: "<a href=\"/cec15681\">page</a>",
+		},
+		{
+			name:     "line break",
+			input:    []notionapi.RichText{{PlainText: "line1\nline2"}},
+			expected: "line1<br>line2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FormatRichTextHTML(NewSpans(tt.input))
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertHTMLUnsafeBookmark(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createBookmarkBlock("javascript:alert(1)"), Indent: 0},
+		{Block: createBookmarkBlock("mailto:someone@example.com"), Indent: 0},
+	}
+
+	result := ConvertHTML(blocks)
+
+	expected := "<p class=\"bookmark\">javascript:alert(1)</p>\n" +
+		"<p class=\"bookmark\"><a href=\"mailto:someone@example.com\">mailto:someone@example.com</a></p>\n"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestHTMLDocument(t *testing.T) {
+	info := PageInfo{
+		Title:          "Q&A",
+		URL:            "https://www.notion.so/page",
+		CreatedTime:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		LastEditedTime: time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC),
+	}
+
+	result := HTMLDocument(info, "<p>Body</p>\n", false)
+
+	for _, want := range []string{
+		"<!DOCTYPE html>\n",
+		"<title>Q&amp;A</title>\n",
+		"<meta name=\"updated\" content=\"2024-01-02T15:30:00Z\">\n",
+		"<body>\n<p>Body</p>\n</body>\n</html>\n",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected %q in %q", want, result)
+		}
+	}
+	if strings.Contains(result, "<style>") {
+		t.Error("Expected no style element")
+	}
+
+	if styled := HTMLDocument(info, "", true); !strings.Contains(styled, "<style>\n") {
+		t.Error("Expected a style element")
+	}
+}
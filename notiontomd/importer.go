@@ -0,0 +1,805 @@
+package notiontomd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jomei/notionapi"
+)
+
+// maxRichTextLength is the longest text content Notion accepts in a rich text object
+const maxRichTextLength = 2000
+
+// maxAppendBlocks is the number of blocks Notion accepts in one append request
+const maxAppendBlocks = 100
+
+var (
+	mdHeading   = regexp.MustCompile(`^(#{1,6})\s+(.*?)(\s+#+)?\s*$`)
+	mdDivider   = regexp.MustCompile(`^ {0,3}((\*\s*){3,}|(-\s*){3,}|(_\s*){3,})$`)
+	mdListItem  = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])(\s+(.*))?$`)
+	mdTask      = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	mdTableRule = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdImage     = regexp.MustCompile(`^!\[([^\]]*)\]\(([^)\s]+)\)$`)
+)
+
+// notionLanguages are the code block languages Notion accepts
+var notionLanguages = map[string]bool{
+	"abap": true, "agda": true, "arduino": true, "assembly": true, "bash": true, "basic": true, "bnf": true,
+	"c": true, "c#": true, "c++": true, "clojure": true, "coffeescript": true, "coq": true, "css": true,
+	"dart": true, "dhall": true, "diff": true, "docker": true, "ebnf": true, "elixir": true, "elm": true,
+	"erlang": true, "f#": true, "flow": true, "fortran": true, "gherkin": true, "glsl": true, "go": true,
+	"graphql": true, "groovy": true, "haskell": true, "html": true, "idris": true, "java": true,
+	"javascript": true, "json": true, "julia": true, "kotlin": true, "latex": true, "less": true,
+	"lisp": true, "livescript": true, "llvm ir": true, "lua": true, "makefile": true, "markdown": true,
+	"markup": true, "matlab": true, "mathematica": true, "mermaid": true, "nix": true,
+	"notion formula": true, "objective-c": true, "ocaml": true, "pascal": true, "perl": true, "php": true,
+	"plain text": true, "powershell": true, "prolog": true, "protobuf": true, "purescript": true,
+	"python": true, "r": true, "racket": true, "reason": true, "ruby": true, "rust": true, "sass": true,
+	"scala": true, "scheme": true, "scss": true, "shell": true, "smalltalk": true, "solidity": true,
+	"sql": true, "swift": true, "toml": true, "typescript": true, "vb.net": true, "verilog": true,
+	"vhdl": true, "visual basic": true, "webassembly": true, "xml": true, "yaml": true,
+}
+
+// languageAliases maps common fenced code info strings to Notion languages
+var languageAliases = map[string]string{
+	"js": "javascript", "jsx": "javascript", "ts": "typescript", "tsx": "typescript",
+	"py": "python", "rb": "ruby", "rs": "rust", "golang": "go", "sh": "shell", "zsh": "shell",
+	"console": "shell", "yml": "yaml", "md": "markdown", "cpp": "c++", "cs": "c#", "csharp": "c#",
+	"dockerfile": "docker", "make": "makefile", "proto": "protobuf", "tex": "latex", "text": "plain text",
+	"txt": "plain text", "objc": "objective-c", "kt": "kotlin", "ps1": "powershell",
+}
+
+// ParseMarkdown converts Markdown into Notion blocks that can be appended to
+// a page. It understands headings, paragraphs, bulleted, numbered and task
+// lists, fenced code, quotes, GFM tables, dividers and images, and in text
+// links, bold, italic, strikethrough and inline code. Front matter is skipped.
+// Images and links that are not http or https URLs, e.g. local files, cannot
+// be written to Notion: images are kept as their Markdown text and links
+// as their label.
+func ParseMarkdown(source string) []notionapi.Block {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	return parseMarkdownBlocks(skipFrontMatter(lines))
+}
+
+// skipFrontMatter returns the lines after a leading YAML front matter
+func skipFrontMatter(lines []string) []string {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return lines
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return lines[i+1:]
+		}
+	}
+	return lines
+}
+
+// parseMarkdownBlocks converts lines at the same nesting level into blocks
+func parseMarkdownBlocks(lines []string) []notionapi.Block {
+	var blocks []notionapi.Block
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			var block notionapi.Block
+			block, i = parseFencedCode(lines, i)
+			blocks = append(blocks, block)
+		case mdHeading.MatchString(trimmed):
+			blocks = append(blocks, newHeadingBlock(mdHeading.FindStringSubmatch(trimmed)))
+			i++
+		case mdDivider.MatchString(line):
+			blocks = append(blocks, &notionapi.DividerBlock{
+				BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeDivider},
+			})
+			i++
+		case strings.HasPrefix(trimmed, ">"):
+			var block notionapi.Block
+			block, i = parseQuote(lines, i)
+			blocks = append(blocks, block)
+		case isTableStart(lines, i):
+			var block notionapi.Block
+			block, i = parseTable(lines, i)
+			blocks = append(blocks, block)
+		case mdListItem.MatchString(line):
+			var block notionapi.Block
+			block, i = parseListItem(lines, i)
+			blocks = append(blocks, block)
+		case mdImage.MatchString(trimmed):
+			m := mdImage.FindStringSubmatch(trimmed)
+			i++
+			if !isWebURL(m[2]) {
+				// Keep the Markdown of local images visible instead of failing the write
+				blocks = append(blocks, &notionapi.ParagraphBlock{
+					BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeParagraph},
+					Paragraph:  notionapi.Paragraph{RichText: plainRichText(trimmed)},
+				})
+				continue
+			}
+			blocks = append(blocks, &notionapi.ImageBlock{
+				BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeImage},
+				Image: notionapi.Image{
+					Type:     notionapi.FileTypeExternal,
+					External: &notionapi.FileObject{URL: m[2]},
+					Caption:  ParseRichText(m[1]),
+				},
+			})
+		default:
+			var block notionapi.Block
+			block, i = parseParagraph(lines, i)
+			blocks = append(blocks, block)
+		}
+	}
+
+	return blocks
+}
+
+// parseFencedCode parses a fenced code block starting at lines[start] and
+// returns it with the index of the line after it
+func parseFencedCode(lines []string, start int) (notionapi.Block, int) {
+	opening := strings.TrimSpace(lines[start])
+	fence := opening[:3]
+	info := strings.TrimSpace(strings.TrimLeft(opening, fence[:1]))
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+			i++
+			break
+		}
+		code = append(code, lines[i])
+	}
+
+	return &notionapi.CodeBlock{
+		BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeCode},
+		Code: notionapi.Code{
+			RichText: plainRichText(strings.Join(code, "\n")),
+			Language: notionLanguage(info),
+		},
+	}, i
+}
+
+// notionLanguage returns the Notion language for a fenced code info string
+func notionLanguage(info string) string {
+	language := strings.ToLower(info)
+	if alias, ok := languageAliases[language]; ok {
+		language = alias
+	}
+	if notionLanguages[language] {
+		return language
+	}
+	return "plain text"
+}
+
+// newHeadingBlock creates a heading from a match of mdHeading. Notion has
+// three heading levels, so deeper headings become heading_3.
+func newHeadingBlock(m []string) notionapi.Block {
+	heading := notionapi.Heading{RichText: ParseRichText(m[2])}
+	basic := func(blockType notionapi.BlockType) notionapi.BasicBlock {
+		return notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: blockType}
+	}
+
+	switch len(m[1]) {
+	case 1:
+		return &notionapi.Heading1Block{BasicBlock: basic(notionapi.BlockTypeHeading1), Heading1: heading}
+	case 2:
+		return &notionapi.Heading2Block{BasicBlock: basic(notionapi.BlockTypeHeading2), Heading2: heading}
+	}
+	return &notionapi.Heading3Block{BasicBlock: basic(notionapi.BlockTypeHeading3), Heading3: heading}
+}
+
+// parseQuote parses consecutive > lines into a quote. Its first paragraph
+// becomes the text of the quote and the remaining blocks its children.
+func parseQuote(lines []string, start int) (notionapi.Block, int) {
+	var inner []string
+	i := start
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(trimmed, ">") {
+			break
+		}
+		trimmed = strings.TrimPrefix(trimmed, ">")
+		inner = append(inner, strings.TrimPrefix(trimmed, " "))
+	}
+
+	quote := notionapi.Quote{RichText: []notionapi.RichText{}}
+	children := parseMarkdownBlocks(inner)
+	if len(children) > 0 {
+		if paragraph, ok := children[0].(*notionapi.ParagraphBlock); ok && len(paragraph.Paragraph.Children) == 0 {
+			quote.RichText = paragraph.Paragraph.RichText
+			children = children[1:]
+		}
+	}
+	quote.Children = children
+
+	return &notionapi.QuoteBlock{
+		BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeQuote},
+		Quote:      quote,
+	}, i
+}
+
+// isTableStart reports whether a GFM table with a header row starts at lines[i]
+func isTableStart(lines []string, i int) bool {
+	return strings.HasPrefix(strings.TrimSpace(lines[i]), "|") &&
+		i+1 < len(lines) && mdTableRule.MatchString(strings.TrimSpace(lines[i+1]))
+}
+
+// parseTable parses a GFM table. The header row becomes the column header.
+func parseTable(lines []string, start int) (notionapi.Block, int) {
+	header := splitTableRow(lines[start])
+	width := len(header)
+	rows := [][]string{header}
+
+	i := start + 2
+	for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+		rows = append(rows, splitTableRow(lines[i]))
+	}
+
+	children := make(notionapi.Blocks, len(rows))
+	for r, row := range rows {
+		cells := make([][]notionapi.RichText, width)
+		for c := range cells {
+			cells[c] = []notionapi.RichText{}
+			if c < len(row) {
+				cells[c] = ParseRichText(row[c])
+			}
+		}
+		children[r] = &notionapi.TableRowBlock{
+			BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeTableRowBlock},
+			TableRow:   notionapi.TableRow{Cells: cells},
+		}
+	}
+
+	return &notionapi.TableBlock{
+		BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeTableBlock},
+		Table: notionapi.Table{
+			TableWidth:      width,
+			HasColumnHeader: true,
+			Children:        children,
+		},
+	}, i
+}
+
+// splitTableRow splits a table row into its cells. Escaped pipes stay in the cell.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// parseListItem parses a list item with its continuation lines and nested
+// blocks, which are the following lines indented more than its marker
+func parseListItem(lines []string, start int) (notionapi.Block, int) {
+	m := mdListItem.FindStringSubmatch(lines[start])
+	indent := len(m[1])
+	text := []string{m[4]}
+
+	i := start + 1
+	// Continuation lines of the item text
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || leadingSpaces(lines[i]) <= indent || startsBlock(lines, i) {
+			break
+		}
+		text = append(text, trimmed)
+	}
+
+	// Nested blocks, including blank lines between them
+	var nested []string
+	for j := i; j < len(lines); j++ {
+		if strings.TrimSpace(lines[j]) == "" {
+			continue
+		}
+		if leadingSpaces(lines[j]) <= indent {
+			break
+		}
+		nested = append(nested, lines[i:j+1]...)
+		i = j + 1
+	}
+	children := parseMarkdownBlocks(dedent(nested))
+
+	content := strings.Join(trimHardBreaks(text), "\n")
+	basic := func(blockType notionapi.BlockType) notionapi.BasicBlock {
+		return notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: blockType}
+	}
+	if task := mdTask.FindStringSubmatch(content); task != nil && !strings.ContainsAny(m[2], "0123456789") {
+		return &notionapi.ToDoBlock{
+			BasicBlock: basic(notionapi.BlockTypeToDo),
+			ToDo: notionapi.ToDo{
+				RichText: ParseRichText(task[2]),
+				Checked:  task[1] != " ",
+				Children: children,
+			},
+		}, i
+	}
+	item := notionapi.ListItem{RichText: ParseRichText(content), Children: children}
+	if strings.ContainsAny(m[2], "0123456789") {
+		return &notionapi.NumberedListItemBlock{BasicBlock: basic(notionapi.BlockTypeNumberedListItem), NumberedListItem: item}, i
+	}
+	return &notionapi.BulletedListItemBlock{BasicBlock: basic(notionapi.BlockTypeBulletedListItem), BulletedListItem: item}, i
+}
+
+// parseParagraph parses lines up to a blank line or the start of another block
+func parseParagraph(lines []string, start int) (notionapi.Block, int) {
+	text := []string{strings.TrimSpace(lines[start])}
+	i := start + 1
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsBlock(lines, i); i++ {
+		text = append(text, strings.TrimSpace(lines[i]))
+	}
+
+	return &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeParagraph},
+		Paragraph:  notionapi.Paragraph{RichText: ParseRichText(strings.Join(trimHardBreaks(text), "\n"))},
+	}, i
+}
+
+// startsBlock reports whether lines[i] starts a block other than a paragraph
+func startsBlock(lines []string, i int) bool {
+	trimmed := strings.TrimSpace(lines[i])
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") ||
+		strings.HasPrefix(trimmed, ">") || mdHeading.MatchString(trimmed) ||
+		mdDivider.MatchString(lines[i]) || mdListItem.MatchString(lines[i]) || isTableStart(lines, i)
+}
+
+// trimHardBreaks removes the trailing backslash or double space that marks
+// a hard line break, since lines are joined with line breaks anyway
+func trimHardBreaks(lines []string) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = strings.TrimSuffix(strings.TrimRight(line, " "), "\\")
+	}
+	return result
+}
+
+// leadingSpaces returns the indentation of a line, counting a tab as four spaces
+func leadingSpaces(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// dedent removes the indentation common to all non-blank lines
+func dedent(lines []string) []string {
+	common := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if n := leadingSpaces(line); common < 0 || n < common {
+			common = n
+		}
+	}
+
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		if len(line) >= common && common > 0 {
+			line = line[common:]
+		}
+		result[i] = line
+	}
+	return result
+}
+
+// asciiPunctuation are the characters that can be escaped with a backslash
+const asciiPunctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// inlineStyle is the formatting applied to a run of text
+type inlineStyle struct {
+	bold, italic, strikethrough, code bool
+	link                              string
+}
+
+// ParseRichText converts Markdown inline text into Notion rich text: links,
+// autolinks, **bold**, *italic*, ~~strikethrough~~ and `code`, with
+// backslash escapes. Runs longer than Notion accepts are split.
+func ParseRichText(text string) []notionapi.RichText {
+	var spans []notionapi.RichText
+	parseInline(text, inlineStyle{}, &spans)
+	if spans == nil {
+		return []notionapi.RichText{}
+	}
+	return spans
+}
+
+// plainRichText returns text as unformatted rich text
+func plainRichText(text string) []notionapi.RichText {
+	var spans []notionapi.RichText
+	appendRichText(&spans, text, inlineStyle{})
+	if spans == nil {
+		return []notionapi.RichText{}
+	}
+	return spans
+}
+
+// parseInline appends the rich text of Markdown inline text with the given style
+func parseInline(text string, style inlineStyle, spans *[]notionapi.RichText) {
+	var literal strings.Builder
+	flush := func() {
+		appendRichText(spans, literal.String(), style)
+		literal.Reset()
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte(asciiPunctuation, text[i+1]) >= 0:
+			literal.WriteByte(text[i+1])
+			i += 2
+			continue
+		case c == '`':
+			n := runLength(text, i, '`')
+			fence := strings.Repeat("`", n)
+			if end := strings.Index(text[i+n:], fence); end >= 0 {
+				flush()
+				code := text[i+n : i+n+end]
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				codeStyle := style
+				codeStyle.code = true
+				appendRichText(spans, code, codeStyle)
+				i += n + end + n
+				continue
+			}
+		case c == '[':
+			if label, url, n := parseLink(text[i:]); n > 0 {
+				flush()
+				linkStyle := style
+				if isWebURL(url) || strings.HasPrefix(url, "mailto:") {
+					linkStyle.link = url
+				}
+				parseInline(label, linkStyle, spans)
+				i += n
+				continue
+			}
+		case c == '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				url := text[i+1 : i+end]
+				if isWebURL(url) && !strings.ContainsAny(url, " <") {
+					flush()
+					linkStyle := style
+					linkStyle.link = url
+					appendRichText(spans, url, linkStyle)
+					i += end + 1
+					continue
+				}
+			}
+		case c == '*' || c == '_' || c == '~':
+			if inner, delimiter, n := parseEmphasis(text, i); n > 0 {
+				flush()
+				innerStyle := style
+				switch delimiter {
+				case "**", "__":
+					innerStyle.bold = true
+				case "~~":
+					innerStyle.strikethrough = true
+				default:
+					innerStyle.italic = true
+				}
+				parseInline(inner, innerStyle, spans)
+				i += n
+				continue
+			}
+		}
+		literal.WriteByte(c)
+		i++
+	}
+	flush()
+}
+
+// isWebURL reports whether url is an absolute http or https URL. Notion
+// rejects relative URLs, e.g. of local images, in images and links.
+func isWebURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// runLength returns how many times c repeats from text[i]
+func runLength(text string, i int, c byte) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == c {
+		n++
+	}
+	return n
+}
+
+// parseLink parses [label](url) at the start of text and returns the label,
+// the URL and the length of the link, or zero when there is no link
+func parseLink(text string) (string, string, int) {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if !strings.HasPrefix(text[i+1:], "(") {
+				return "", "", 0
+			}
+			end := strings.IndexByte(text[i+2:], ')')
+			if end < 0 {
+				return "", "", 0
+			}
+			url := strings.TrimSpace(text[i+2 : i+2+end])
+			// Drop an optional title
+			if space := strings.IndexAny(url, " \t"); space >= 0 {
+				url = url[:space]
+			}
+			return text[1:i], strings.Trim(url, "<>"), i + 3 + end
+		}
+	}
+	return "", "", 0
+}
+
+// parseEmphasis parses emphasis starting at text[i] and returns the inner
+// text, the delimiter and the length including delimiters, or zero when
+// the delimiter is not closed
+func parseEmphasis(text string, i int) (string, string, int) {
+	c := text[i]
+	delimiter := string(c)
+	if runLength(text, i, c) >= 2 {
+		delimiter = string([]byte{c, c})
+	} else if c == '~' {
+		return "", "", 0
+	}
+	n := len(delimiter)
+
+	// Opening delimiters must be followed by text, and underscores must not
+	// be inside a word such as snake_case
+	if i+n >= len(text) || text[i+n] == ' ' {
+		return "", "", 0
+	}
+	if c == '_' && i > 0 && isWordByte(text, i-1) {
+		return "", "", 0
+	}
+
+	for j := i + n + 1; j+n <= len(text); j++ {
+		if text[j-1] == '\\' {
+			continue
+		}
+		if text[j:j+n] != delimiter || text[j-1] == ' ' {
+			continue
+		}
+		// Skip delimiters that continue into a longer run, e.g. the first
+		// asterisk of the ** closing ***bold italic***
+		if j+n < len(text) && text[j+n] == c {
+			continue
+		}
+		if c == '_' && j+n < len(text) && isWordByte(text, j+n) {
+			continue
+		}
+		return text[i+n : j], delimiter, j + n - i
+	}
+	return "", "", 0
+}
+
+// isWordByte reports whether the rune containing text[i] is a letter or digit
+func isWordByte(text string, i int) bool {
+	for i > 0 && !utf8.RuneStart(text[i]) {
+		i--
+	}
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// appendRichText appends text with a style, merging it into the previous
+// run when the style is the same and splitting runs that are too long
+func appendRichText(spans *[]notionapi.RichText, text string, style inlineStyle) {
+	if text == "" {
+		return
+	}
+	if n := len(*spans); n > 0 && richTextStyle((*spans)[n-1]) == style {
+		last := &(*spans)[n-1]
+		text = last.Text.Content + text
+		*spans = (*spans)[:n-1]
+	}
+
+	for text != "" {
+		chunk := text
+		if utf8.RuneCountInString(chunk) > maxRichTextLength {
+			chunk = string([]rune(chunk)[:maxRichTextLength])
+		}
+		text = text[len(chunk):]
+
+		rt := notionapi.RichText{Type: notionapi.ObjectTypeText, Text: &notionapi.Text{Content: chunk}}
+		if style.link != "" {
+			rt.Text.Link = &notionapi.Link{Url: style.link}
+		}
+		if style.bold || style.italic || style.strikethrough || style.code {
+			rt.Annotations = &notionapi.Annotations{
+				Bold:          style.bold,
+				Italic:        style.italic,
+				Strikethrough: style.strikethrough,
+				Code:          style.code,
+				Color:         notionapi.ColorDefault,
+			}
+		}
+		*spans = append(*spans, rt)
+	}
+}
+
+// richTextStyle returns the style of rich text created by appendRichText
+func richTextStyle(rt notionapi.RichText) inlineStyle {
+	var style inlineStyle
+	if rt.Text != nil && rt.Text.Link != nil {
+		style.link = rt.Text.Link.Url
+	}
+	if a := rt.Annotations; a != nil {
+		style.bold = a.Bold
+		style.italic = a.Italic
+		style.strikethrough = a.Strikethrough
+		style.code = a.Code
+	}
+	return style
+}
+
+// BlockWriter is the part of the Notion block API that WriteBlocks uses
+type BlockWriter interface {
+	BlockFetcher
+	AppendChildren(ctx context.Context, blockID notionapi.BlockID, request *notionapi.AppendBlockChildrenRequest) (*notionapi.AppendBlockChildrenResponse, error)
+	Delete(ctx context.Context, blockID notionapi.BlockID) (notionapi.Block, error)
+}
+
+// WriteOptions configures WriteBlocks
+type WriteOptions struct {
+	// Replace deletes the existing children of the page once the new blocks
+	// are appended. Child pages and databases are kept, above the new blocks.
+	Replace bool
+}
+
+// WriteBlocks appends blocks to a page, or replaces its content when
+// opts.Replace is set. Nested blocks are appended level by level, since
+// Notion limits how deeply blocks can be nested in a single request.
+// When a request fails, the blocks already appended are deleted again, and
+// when replacing, the existing blocks are only deleted after every new
+// block was appended, so a failed write leaves the page as it was.
+func WriteBlocks(ctx context.Context, writer BlockWriter, pageID notionapi.BlockID, blocks []notionapi.Block, opts WriteOptions) error {
+	var existing []notionapi.Block
+	if opts.Replace {
+		var err error
+		if existing, err = fetchBlockChildren(ctx, writer, pageID); err != nil {
+			return err
+		}
+	}
+
+	created, err := appendBlocks(ctx, writer, pageID, blocks)
+	if err != nil {
+		var ids []notionapi.BlockID
+		for _, block := range created {
+			ids = append(ids, block.GetID())
+		}
+		if rollbackErr := deleteBlocks(ctx, writer, ids); rollbackErr != nil {
+			return fmt.Errorf("%w (some of the new blocks could not be removed and remain on the page: %w)", err, rollbackErr)
+		}
+		return err
+	}
+
+	var old []notionapi.BlockID
+	for _, block := range existing {
+		switch block.GetType() {
+		case notionapi.BlockTypeChildPage, notionapi.BlockTypeChildDatabase:
+			// Deleting them would move whole pages and databases to the trash
+			continue
+		}
+		old = append(old, block.GetID())
+	}
+	return deleteBlocks(ctx, writer, old)
+}
+
+// deleteBlocks deletes blocks, stopping at the first failure
+func deleteBlocks(ctx context.Context, writer BlockWriter, ids []notionapi.BlockID) error {
+	for _, id := range ids {
+		if _, err := writer.Delete(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete block %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// appendBlocks appends blocks without their children in batches, then
+// appends the children to the created blocks. It returns the blocks created
+// directly under parentID, also when it fails partway.
+func appendBlocks(ctx context.Context, writer BlockWriter, parentID notionapi.BlockID, blocks []notionapi.Block) ([]notionapi.Block, error) {
+	var created []notionapi.Block
+	for start := 0; start < len(blocks); start += maxAppendBlocks {
+		end := min(start+maxAppendBlocks, len(blocks))
+
+		batch := make([]notionapi.Block, 0, end-start)
+		var children []notionapi.Blocks
+		for _, block := range blocks[start:end] {
+			block, nested := splitChildren(block)
+			batch = append(batch, block)
+			children = append(children, nested)
+		}
+
+		resp, err := writer.AppendChildren(ctx, parentID, &notionapi.AppendBlockChildrenRequest{Children: batch})
+		if err != nil {
+			return created, fmt.Errorf("failed to append blocks to %s: %w", parentID, err)
+		}
+		created = append(created, resp.Results...)
+		if len(resp.Results) != len(batch) {
+			return created, fmt.Errorf("failed to append blocks to %s: expected %d results, got %d", parentID, len(batch), len(resp.Results))
+		}
+		for i, nested := range children {
+			if len(nested) == 0 {
+				continue
+			}
+			// Nested blocks are deleted with their parent, so only the
+			// blocks under parentID are returned
+			if _, err := appendBlocks(ctx, writer, resp.Results[i].GetID(), nested); err != nil {
+				return created, err
+			}
+		}
+	}
+	return created, nil
+}
+
+// splitChildren returns a copy of a block without its nested blocks, and
+// the nested blocks. Table rows stay in the table, which cannot be created
+// without them.
+func splitChildren(block notionapi.Block) (notionapi.Block, notionapi.Blocks) {
+	switch b := block.(type) {
+	case *notionapi.ParagraphBlock:
+		copied := *b
+		copied.Paragraph.Children = nil
+		return &copied, b.Paragraph.Children
+	case *notionapi.QuoteBlock:
+		copied := *b
+		copied.Quote.Children = nil
+		return &copied, b.Quote.Children
+	case *notionapi.BulletedListItemBlock:
+		copied := *b
+		copied.BulletedListItem.Children = nil
+		return &copied, b.BulletedListItem.Children
+	case *notionapi.NumberedListItemBlock:
+		copied := *b
+		copied.NumberedListItem.Children = nil
+		return &copied, b.NumberedListItem.Children
+	case *notionapi.ToDoBlock:
+		copied := *b
+		copied.ToDo.Children = nil
+		return &copied, b.ToDo.Children
+	}
+	return block, nil
+}
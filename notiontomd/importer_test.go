@@ -0,0 +1,316 @@
+package notiontomd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// mockBlockWriter records the blocks appended and deleted by WriteBlocks
+type mockBlockWriter struct {
+	mockBlockFetcher
+	appended map[notionapi.BlockID][]notionapi.Block
+	deleted  []notionapi.BlockID
+	created  int
+	// appendErr fails the append requests after the first appendLimit
+	appendErr   error
+	appendLimit int
+	appends     int
+	// deletedAfter is the number of blocks created when the first block was deleted
+	deletedAfter int
+}
+
+func (m *mockBlockWriter) AppendChildren(ctx context.Context, blockID notionapi.BlockID, request *notionapi.AppendBlockChildrenRequest) (*notionapi.AppendBlockChildrenResponse, error) {
+	if m.appendErr != nil && m.appends >= m.appendLimit {
+		return nil, m.appendErr
+	}
+	m.appends++
+	if m.appended == nil {
+		m.appended = make(map[notionapi.BlockID][]notionapi.Block)
+	}
+	m.appended[blockID] = append(m.appended[blockID], request.Children...)
+
+	resp := &notionapi.AppendBlockChildrenResponse{}
+	for range request.Children {
+		m.created++
+		resp.Results = append(resp.Results, createParagraphBlock(fmt.Sprintf("created-%d", m.created), false))
+	}
+	return resp, nil
+}
+
+func (m *mockBlockWriter) Delete(ctx context.Context, blockID notionapi.BlockID) (notionapi.Block, error) {
+	if len(m.deleted) == 0 {
+		m.deletedAfter = m.created
+	}
+	m.deleted = append(m.deleted, blockID)
+	return nil, nil
+}
+
+func TestParseRichText(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []Span
+	}{
+		{"plain", []Span{{Type: "text", Text: "plain"}}},
+		{"a **bold** word", []Span{
+			{Type: "text", Text: "a "},
+			{Type: "text", Text: "bold", Bold: true},
+			{Type: "text", Text: " word"},
+		}},
+		{"***both*** and ~~gone~~", []Span{
+			{Type: "text", Text: "both", Bold: true, Italic: true},
+			{Type: "text", Text: " and "},
+			{Type: "text", Text: "gone", Strikethrough: true},
+		}},
+		{"see [the **docs**](https://example.com)", []Span{
+			{Type: "text", Text: "see "},
+			{Type: "text", Text: "the ", Href: "https://example.com"},
+			{Type: "text", Text: "docs", Bold: true, Href: "https://example.com"},
+		}},
+		{"`a*b*` and snake_case_name", []Span{
+			{Type: "text", Text: "a*b*", Code: true},
+			{Type: "text", Text: " and snake_case_name"},
+		}},
+		{`\*not italic\* <https://go.dev>`, []Span{
+			{Type: "text", Text: "*not italic* "},
+			{Type: "text", Text: "https://go.dev", Href: "https://go.dev"},
+		}},
+	}
+
+	for _, tt := range tests {
+		result := NewSpans(ParseRichText(tt.input))
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("%q: expected %+v, got %+v", tt.input, tt.expected, result)
+		}
+	}
+}
+
+func TestParseRichTextSplitsLongText(t *testing.T) {
+	spans := ParseRichText(strings.Repeat("あ", maxRichTextLength+1))
+
+	if len(spans) != 2 || len([]rune(spans[1].Text.Content)) != 1 {
+		t.Errorf("Expected the text to be split at %d characters, got %d spans", maxRichTextLength, len(spans))
+	}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	source := `---
+title: "Ignored"
+---
+
+# Title
+
+First line
+second line
+
+- item
+  - nested **item**
+- [x] done
+- [ ] todo
+
+1. one
+2. two
+
+` + "```js\nconst a = 1;\n```" + `
+
+> quoted
+> text
+
+| Name | Value |
+| --- | ---: |
+| a \| b | 1 |
+
+---
+
+![A diagram](https://example.com/a.png)
+`
+
+	blocks := ParseMarkdown(source)
+
+	var types []notionapi.BlockType
+	for _, block := range blocks {
+		types = append(types, block.GetType())
+	}
+	expectedTypes := []notionapi.BlockType{
+		notionapi.BlockTypeHeading1,
+		notionapi.BlockTypeParagraph,
+		notionapi.BlockTypeBulletedListItem,
+		notionapi.BlockTypeToDo,
+		notionapi.BlockTypeToDo,
+		notionapi.BlockTypeNumberedListItem,
+		notionapi.BlockTypeNumberedListItem,
+		notionapi.BlockTypeCode,
+		notionapi.BlockTypeQuote,
+		notionapi.BlockTypeTableBlock,
+		notionapi.BlockTypeDivider,
+		notionapi.BlockTypeImage,
+	}
+	if !reflect.DeepEqual(types, expectedTypes) {
+		t.Fatalf("Expected %v, got %v", expectedTypes, types)
+	}
+
+	if text := PlainText(NewSpans(blocks[1].(*notionapi.ParagraphBlock).Paragraph.RichText)); text != "First line\nsecond line" {
+		t.Errorf("Expected %q, got %q", "First line\nsecond line", text)
+	}
+
+	item := blocks[2].(*notionapi.BulletedListItemBlock).BulletedListItem
+	if len(item.Children) != 1 || item.Children[0].GetType() != notionapi.BlockTypeBulletedListItem {
+		t.Errorf("Expected a nested list item, got %+v", item.Children)
+	}
+
+	if !blocks[3].(*notionapi.ToDoBlock).ToDo.Checked || blocks[4].(*notionapi.ToDoBlock).ToDo.Checked {
+		t.Error("Expected the first task to be checked and the second not")
+	}
+
+	code := blocks[7].(*notionapi.CodeBlock).Code
+	if code.Language != "javascript" || PlainText(NewSpans(code.RichText)) != "const a = 1;" {
+		t.Errorf("Expected javascript code, got %+v", code)
+	}
+
+	if text := PlainText(NewSpans(blocks[8].(*notionapi.QuoteBlock).Quote.RichText)); text != "quoted\ntext" {
+		t.Errorf("Expected %q, got %q", "quoted\ntext", text)
+	}
+
+	table := blocks[9].(*notionapi.TableBlock).Table
+	if table.TableWidth != 2 || !table.HasColumnHeader || len(table.Children) != 2 {
+		t.Fatalf("Expected a table with a header and a row, got %+v", table)
+	}
+	cell := table.Children[1].(*notionapi.TableRowBlock).TableRow.Cells[0]
+	if text := PlainText(NewSpans(cell)); text != "a | b" {
+		t.Errorf("Expected %q, got %q", "a | b", text)
+	}
+}
+
+func TestParseMarkdownUnknownLanguage(t *testing.T) {
+	blocks := ParseMarkdown("```\nplain\n```\n\n```brainfuck\n+\n```\n")
+
+	for _, block := range blocks {
+		if language := block.(*notionapi.CodeBlock).Code.Language; language != "plain text" {
+			t.Errorf("Expected %q, got %q", "plain text", language)
+		}
+	}
+}
+
+func TestParseMarkdownLocalImagesAndLinks(t *testing.T) {
+	blocks := ParseMarkdown("![x](images/a.png)\n\n![y](https://example.com/b.png)\n\nsee [notes](./notes.md)\n")
+
+	if len(blocks) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d", len(blocks))
+	}
+	paragraph, ok := blocks[0].(*notionapi.ParagraphBlock)
+	if !ok {
+		t.Fatalf("Expected a local image to become a paragraph, got %T", blocks[0])
+	}
+	if text := PlainText(NewSpans(paragraph.Paragraph.RichText)); text != "![x](images/a.png)" {
+		t.Errorf("Expected %q, got %q", "![x](images/a.png)", text)
+	}
+	if _, ok := blocks[1].(*notionapi.ImageBlock); !ok {
+		t.Errorf("Expected an image block, got %T", blocks[1])
+	}
+	spans := NewSpans(blocks[2].(*notionapi.ParagraphBlock).Paragraph.RichText)
+	for _, span := range spans {
+		if span.Href != "" {
+			t.Errorf("Expected no link to a relative URL, got %q", span.Href)
+		}
+	}
+	if text := PlainText(spans); text != "see notes" {
+		t.Errorf("Expected %q, got %q", "see notes", text)
+	}
+}
+
+func TestWriteBlocks(t *testing.T) {
+	writer := &mockBlockWriter{
+		mockBlockFetcher: mockBlockFetcher{
+			responses: []*notionapi.GetChildrenResponse{{
+				Results: []notionapi.Block{
+					createParagraphBlock("old-1", false),
+					createChildPageBlock("child-page"),
+					createParagraphBlock("old-2", false),
+				},
+			}},
+		},
+	}
+	blocks := ParseMarkdown("- parent\n  - child\n    - grandchild\n\nafter\n")
+
+	if err := WriteBlocks(context.Background(), writer, "page-1", blocks, WriteOptions{Replace: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(writer.deleted, []notionapi.BlockID{"old-1", "old-2"}) {
+		t.Errorf("Expected the existing blocks except child pages to be deleted, got %v", writer.deleted)
+	}
+	if writer.deletedAfter != 4 {
+		t.Errorf("Expected the existing blocks to be deleted after the 4 new blocks were created, got %d", writer.deletedAfter)
+	}
+	if len(writer.appended["page-1"]) != 2 {
+		t.Fatalf("Expected 2 blocks appended to the page, got %d", len(writer.appended["page-1"]))
+	}
+	parent := writer.appended["page-1"][0].(*notionapi.BulletedListItemBlock)
+	if len(parent.BulletedListItem.Children) != 0 {
+		t.Error("Expected nested blocks to be appended separately")
+	}
+	if len(blocks[0].(*notionapi.BulletedListItemBlock).BulletedListItem.Children) != 1 {
+		t.Error("Expected the parsed blocks to be unchanged")
+	}
+	// The parent is created first, then its child, then the grandchild
+	if len(writer.appended["created-1"]) != 1 || len(writer.appended["created-3"]) != 1 {
+		t.Errorf("Expected the children to be appended level by level, got %v", writer.appended)
+	}
+}
+
+func TestWriteBlocksReplaceFailedAppend(t *testing.T) {
+	writer := &mockBlockWriter{
+		mockBlockFetcher: mockBlockFetcher{
+			responses: []*notionapi.GetChildrenResponse{{
+				Results: []notionapi.Block{createParagraphBlock("old-1", false)},
+			}},
+		},
+		appendErr: errors.New("rate limited"),
+	}
+
+	err := WriteBlocks(context.Background(), writer, "page-1", ParseMarkdown("new\n"), WriteOptions{Replace: true})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if len(writer.deleted) != 0 {
+		t.Errorf("Expected the existing blocks to be kept, got %v deleted", writer.deleted)
+	}
+}
+
+func TestWriteBlocksRemovesPartialContent(t *testing.T) {
+	writer := &mockBlockWriter{
+		mockBlockFetcher: mockBlockFetcher{
+			responses: []*notionapi.GetChildrenResponse{{
+				Results: []notionapi.Block{createParagraphBlock("old-1", false)},
+			}},
+		},
+		appendErr:   errors.New("rate limited"),
+		appendLimit: 1,
+	}
+
+	// The nested item is appended in a second request, which fails
+	err := WriteBlocks(context.Background(), writer, "page-1", ParseMarkdown("- parent\n  - child\n\nafter\n"), WriteOptions{Replace: true})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	expected := []notionapi.BlockID{"created-1", "created-2"}
+	if !reflect.DeepEqual(writer.deleted, expected) {
+		t.Errorf("Expected the new blocks to be removed and the existing ones kept, got %v deleted", writer.deleted)
+	}
+}
+
+// Helper function to create a child page block
+func createChildPageBlock(id string) notionapi.Block {
+	return &notionapi.ChildPageBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     notionapi.BlockID(id),
+			Type:   notionapi.BlockTypeChildPage,
+		},
+	}
+}
@@ -0,0 +1,351 @@
+package notiontomd
+
+import (
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// TreeVersion is the version of the JSON representation of Tree. It is
+// increased when fields are renamed or removed.
+const TreeVersion = 1
+
+// Tree is the intermediate representation of a page that every output
+// format is rendered from. It is also written as is by the JSON format.
+type Tree struct {
+	Version int      `json:"version"`
+	Page    PageInfo `json:"page"`
+	Blocks  []*Node  `json:"blocks"`
+}
+
+// Node is a block with its nested blocks. Only the fields that apply to
+// the block type are set.
+type Node struct {
+	// ID is the Notion block ID
+	ID string `json:"id,omitempty"`
+	// Type is the Notion block type, e.g. "paragraph" or "bulleted_list_item"
+	Type notionapi.BlockType `json:"type"`
+	// Text is the rich text of text blocks such as paragraphs, headings,
+	// list items, to-dos, toggles, quotes, callouts and code
+	Text []Span `json:"text,omitempty"`
+	// Caption is the caption of code, image, bookmark, embed and file blocks
+	Caption []Span `json:"caption,omitempty"`
+	// Language is the language of a code block
+	Language string `json:"language,omitempty"`
+	// Checked is set for checked to-dos
+	Checked bool `json:"checked,omitempty"`
+	// Toggleable is set for headings that can be collapsed
+	Toggleable bool `json:"toggleable,omitempty"`
+	// Icon is the emoji or icon URL of a callout
+	Icon string `json:"icon,omitempty"`
+	// URL is the target of image, video, audio, file, pdf, bookmark and embed blocks
+	URL string `json:"url,omitempty"`
+	// Expression is the TeX source of an equation block
+	Expression string `json:"expression,omitempty"`
+	// Title is the title of a child page or child database, or of the page
+	// a link_to_page block points to when Options.ResolveLinkTitles is set
+	Title string `json:"title,omitempty"`
+	// PageID is the page or database a link_to_page block points to
+	PageID string `json:"page_id,omitempty"`
+	// ColumnHeader and RowHeader mark the first row and column of a table as headers
+	ColumnHeader bool `json:"column_header,omitempty"`
+	RowHeader    bool `json:"row_header,omitempty"`
+	// Cells are the cells of a table row
+	Cells [][]Span `json:"cells,omitempty"`
+	// Truncated is set when nested blocks were omitted by the depth limit
+	Truncated bool    `json:"truncated,omitempty"`
+	Children  []*Node `json:"children,omitempty"`
+	// Block is the block the node was created from
+	Block notionapi.Block `json:"-"`
+	// indent is the indent of the fetched block, used as the depth of top-level nodes
+	indent int
+}
+
+// Span is a run of rich text with the same formatting
+type Span struct {
+	// Type is "text", "mention" or "equation"
+	Type string `json:"type"`
+	// Text is the displayed text, or the TeX source of an inline equation
+	Text          string `json:"text"`
+	Bold          bool   `json:"bold,omitempty"`
+	Italic        bool   `json:"italic,omitempty"`
+	Strikethrough bool   `json:"strikethrough,omitempty"`
+	Underline     bool   `json:"underline,omitempty"`
+	Code          bool   `json:"code,omitempty"`
+	// Color is omitted for the default color
+	Color string `json:"color,omitempty"`
+	Href  string `json:"href,omitempty"`
+	// Mention is set for mentions
+	Mention *Mention `json:"mention,omitempty"`
+}
+
+// Mention is the target of a mention in rich text
+type Mention struct {
+	// Type is "page", "database", "user", "date" or "template_mention"
+	Type string `json:"type"`
+	// ID is the page, database or user that is mentioned
+	ID string `json:"id,omitempty"`
+}
+
+// NewTree creates the intermediate representation of a page from its fetched blocks
+func NewTree(info PageInfo, blocks []BlockWithIndent) *Tree {
+	return &Tree{
+		Version: TreeVersion,
+		Page:    info,
+		Blocks:  NewNodes(blocks),
+	}
+}
+
+// NewNodes nests the flat list returned by FetchAllBlocks into a tree,
+// placing each block under the closest preceding block with a smaller indent
+func NewNodes(blocks []BlockWithIndent) []*Node {
+	roots := []*Node{}
+	var stack []*Node
+	var indents []int
+
+	for _, bwi := range blocks {
+		node := newNode(bwi)
+		for len(stack) > 0 && indents[len(indents)-1] >= bwi.Indent {
+			stack = stack[:len(stack)-1]
+			indents = indents[:len(indents)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+		}
+		stack = append(stack, node)
+		indents = append(indents, bwi.Indent)
+	}
+
+	return roots
+}
+
+// newNode converts a single block without its children
+func newNode(bwi BlockWithIndent) *Node {
+	node := &Node{
+		ID:        string(bwi.Block.GetID()),
+		Type:      bwi.Block.GetType(),
+		Truncated: bwi.Truncated,
+		Block:     bwi.Block,
+		indent:    bwi.Indent,
+	}
+
+	switch b := bwi.Block.(type) {
+	case *notionapi.Heading1Block:
+		node.Text = NewSpans(b.Heading1.RichText)
+		node.Toggleable = b.Heading1.IsToggleable
+	case *notionapi.Heading2Block:
+		node.Text = NewSpans(b.Heading2.RichText)
+		node.Toggleable = b.Heading2.IsToggleable
+	case *notionapi.Heading3Block:
+		node.Text = NewSpans(b.Heading3.RichText)
+		node.Toggleable = b.Heading3.IsToggleable
+	case *notionapi.ParagraphBlock:
+		node.Text = NewSpans(b.Paragraph.RichText)
+	case *notionapi.BulletedListItemBlock:
+		node.Text = NewSpans(b.BulletedListItem.RichText)
+	case *notionapi.NumberedListItemBlock:
+		node.Text = NewSpans(b.NumberedListItem.RichText)
+	case *notionapi.ToDoBlock:
+		node.Text = NewSpans(b.ToDo.RichText)
+		node.Checked = b.ToDo.Checked
+	case *notionapi.ToggleBlock:
+		node.Text = NewSpans(b.Toggle.RichText)
+	case *notionapi.QuoteBlock:
+		node.Text = NewSpans(b.Quote.RichText)
+	case *notionapi.CalloutBlock:
+		node.Text = NewSpans(b.Callout.RichText)
+		if icon := b.Callout.Icon; icon != nil {
+			if icon.Emoji != nil {
+				node.Icon = string(*icon.Emoji)
+			} else {
+				node.Icon = icon.GetURL()
+			}
+		}
+	case *notionapi.CodeBlock:
+		node.Text = NewSpans(b.Code.RichText)
+		node.Caption = NewSpans(b.Code.Caption)
+		node.Language = b.Code.Language
+	case *notionapi.EquationBlock:
+		node.Expression = b.Equation.Expression
+	case *notionapi.ImageBlock:
+		node.URL = b.Image.GetURL()
+		node.Caption = NewSpans(b.Image.Caption)
+	case *notionapi.VideoBlock:
+		node.URL = fileURL(b.Video.File, b.Video.External)
+		node.Caption = NewSpans(b.Video.Caption)
+	case *notionapi.AudioBlock:
+		node.URL = b.Audio.GetURL()
+		node.Caption = NewSpans(b.Audio.Caption)
+	case *notionapi.FileBlock:
+		node.URL = fileURL(b.File.File, b.File.External)
+		node.Caption = NewSpans(b.File.Caption)
+	case *notionapi.PdfBlock:
+		node.URL = fileURL(b.Pdf.File, b.Pdf.External)
+		node.Caption = NewSpans(b.Pdf.Caption)
+	case *notionapi.BookmarkBlock:
+		node.URL = b.Bookmark.URL
+		node.Caption = NewSpans(b.Bookmark.Caption)
+	case *notionapi.EmbedBlock:
+		node.URL = b.Embed.URL
+		node.Caption = NewSpans(b.Embed.Caption)
+	case *notionapi.ChildPageBlock:
+		node.Title = b.ChildPage.Title
+	case *notionapi.ChildDatabaseBlock:
+		node.Title = b.ChildDatabase.Title
+	case *notionapi.LinkToPageBlock:
+		if b.LinkToPage.PageID != "" {
+			node.PageID = string(b.LinkToPage.PageID)
+		} else {
+			node.PageID = string(b.LinkToPage.DatabaseID)
+		}
+	case *notionapi.TableBlock:
+		node.ColumnHeader = b.Table.HasColumnHeader
+		node.RowHeader = b.Table.HasRowHeader
+	case *notionapi.TableRowBlock:
+		for _, cell := range b.TableRow.Cells {
+			spans := NewSpans(cell)
+			if spans == nil {
+				spans = []Span{}
+			}
+			node.Cells = append(node.Cells, spans)
+		}
+	}
+
+	return node
+}
+
+// fileURL returns the URL of a Notion-hosted or external file
+func fileURL(file, external *notionapi.FileObject) string {
+	if file != nil {
+		return file.URL
+	}
+	if external != nil {
+		return external.URL
+	}
+	return ""
+}
+
+// NewSpans normalizes Notion rich text. Adjacent text with the same
+// formatting is merged into a single span and empty text is dropped.
+func NewSpans(richTexts []notionapi.RichText) []Span {
+	var spans []Span
+
+	for _, rt := range richTexts {
+		span := Span{
+			Type: string(rt.Type),
+			Text: rt.PlainText,
+			Href: rt.Href,
+		}
+		if span.Type == "" {
+			span.Type = "text"
+		}
+		// Rich text built for requests, e.g. by ParseRichText, only has the text object
+		if t := rt.Text; t != nil {
+			if span.Text == "" {
+				span.Text = t.Content
+			}
+			if span.Href == "" && t.Link != nil {
+				span.Href = t.Link.Url
+			}
+		}
+		if a := rt.Annotations; a != nil {
+			span.Bold = a.Bold
+			span.Italic = a.Italic
+			span.Strikethrough = a.Strikethrough
+			span.Underline = a.Underline
+			span.Code = a.Code
+			if a.Color != "" && a.Color != notionapi.ColorDefault {
+				span.Color = string(a.Color)
+			}
+		}
+		if m := rt.Mention; m != nil {
+			span.Mention = &Mention{Type: string(m.Type)}
+			switch {
+			case m.Page != nil:
+				span.Mention.ID = string(m.Page.ID)
+			case m.Database != nil:
+				span.Mention.ID = string(m.Database.ID)
+			case m.User != nil:
+				span.Mention.ID = string(m.User.ID)
+			}
+		}
+
+		if span.Text == "" {
+			continue
+		}
+		if n := len(spans); n > 0 && mergeable(spans[n-1], span) {
+			spans[n-1].Text += span.Text
+			continue
+		}
+		spans = append(spans, span)
+	}
+
+	return spans
+}
+
+// mergeable reports whether two adjacent spans render the same way when joined
+func mergeable(a, b Span) bool {
+	if a.Type != "text" || b.Type != "text" {
+		return false
+	}
+	a.Text, b.Text = "", ""
+	return a == b
+}
+
+// PlainText concatenates the text of spans without formatting
+func PlainText(spans []Span) string {
+	var result strings.Builder
+	for _, span := range spans {
+		result.WriteString(span.Text)
+	}
+	return result.String()
+}
+
+// isListItem reports whether consecutive blocks of the type form a list
+func isListItem(blockType notionapi.BlockType) bool {
+	switch blockType {
+	case notionapi.BlockTypeBulletedListItem, notionapi.BlockTypeNumberedListItem, notionapi.BlockTypeToDo:
+		return true
+	}
+	return false
+}
+
+// isListOrToggle reports whether the block is written as a list item by
+// formats without toggles, such as Markdown, Org-mode and plain text
+func isListOrToggle(node *Node) bool {
+	return isListItem(node.Type) || node.Type == notionapi.BlockTypeToggle
+}
+
+// headingLevel returns 1 to 3 for headings and 0 for other blocks
+func headingLevel(blockType notionapi.BlockType) int {
+	switch blockType {
+	case notionapi.BlockTypeHeading1:
+		return 1
+	case notionapi.BlockTypeHeading2:
+		return 2
+	case notionapi.BlockTypeHeading3:
+		return 3
+	}
+	return 0
+}
+
+// sourceLanguage converts a Notion code language such as "plain text" or
+// "objective-c" to the identifier used by source blocks
+func sourceLanguage(language string) string {
+	if language == "plain text" {
+		return ""
+	}
+	return strings.ReplaceAll(language, " ", "-")
+}
+
+// calloutPrefix returns the emoji icon of a callout followed by a space, or
+// nothing for callouts without an emoji
+func calloutPrefix(node *Node) string {
+	if node.Icon == "" || strings.Contains(node.Icon, "://") {
+		return ""
+	}
+	return node.Icon + " "
+}
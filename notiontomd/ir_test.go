@@ -0,0 +1,143 @@
+package notiontomd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestNewNodes(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createBulletedListBlock("block-1", "Parent", true), Indent: 0},
+		{Block: createBulletedListBlock("block-2", "Child", true), Indent: 1},
+		{Block: createBulletedListBlock("block-3", "Grandchild", false), Indent: 2},
+		{Block: createBulletedListBlock("block-4", "Second child", false), Indent: 1},
+		{Block: createParagraphBlock("block-5", false), Indent: 0},
+	}
+
+	nodes := NewNodes(blocks)
+
+	if len(nodes) != 2 {
+		t.Fatalf("Expected 2 top-level nodes, got %d", len(nodes))
+	}
+	if nodes[0].ID != "block-1" || nodes[1].ID != "block-5" {
+		t.Errorf("Expected block-1 and block-5 at the top level, got %s and %s", nodes[0].ID, nodes[1].ID)
+	}
+	children := nodes[0].Children
+	if len(children) != 2 || children[0].ID != "block-2" || children[1].ID != "block-4" {
+		t.Fatalf("Expected block-2 and block-4 under block-1, got %+v", children)
+	}
+	if len(children[0].Children) != 1 || children[0].Children[0].ID != "block-3" {
+		t.Errorf("Expected block-3 under block-2, got %+v", children[0].Children)
+	}
+	if got := PlainText(children[0].Children[0].Text); got != "Grandchild" {
+		t.Errorf("Expected %q, got %q", "Grandchild", got)
+	}
+}
+
+func TestNewSpans(t *testing.T) {
+	bold := &notionapi.Annotations{Bold: true, Color: notionapi.ColorDefault}
+	input := []notionapi.RichText{
+		{Type: "text", PlainText: "Hello ", Annotations: bold},
+		{Type: "text", PlainText: "world", Annotations: bold},
+		{Type: "text", PlainText: ""},
+		{Type: "text", PlainText: "!", Annotations: &notionapi.Annotations{Color: notionapi.ColorRed}},
+		{
+			Type:      "mention",
+			PlainText: "Other page",
+			Href:      "https://www.notion.so/abc",
+			Mention: &notionapi.Mention{
+				Type: notionapi.MentionTypePage,
+				Page: &notionapi.PageMention{ID: "abc"},
+			},
+		},
+		{Type: "equation", PlainText: "E=mc^2"},
+	}
+
+	result := NewSpans(input)
+	expected := []Span{
+		{Type: "text", Text: "Hello world", Bold: true},
+		{Type: "text", Text: "!", Color: "red"},
+		{Type: "mention", Text: "Other page", Href: "https://www.notion.so/abc", Mention: &Mention{Type: "page", ID: "abc"}},
+		{Type: "equation", Text: "E=mc^2"},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestConverterJSON(t *testing.T) {
+	info := PageInfo{
+		ID:             "page-1",
+		Title:          "Page",
+		URL:            "https://www.notion.so/page-1",
+		CreatedTime:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		LastEditedTime: time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC),
+	}
+	blocks := []BlockWithIndent{
+		{Block: createBulletedListBlock("block-1", "Item", true), Indent: 0},
+		{Block: createParagraphBlock("block-2", false), Indent: 1},
+	}
+
+	doc := (&Converter{opts: Options{Format: FormatJSON}}).mustRender(t, info, blocks)
+
+	expected := `{
+  "version": 1,
+  "page": {
+    "id": "page-1",
+    "title": "Page",
+    "url": "https://www.notion.so/page-1",
+    "created_time": "2024-01-01T12:00:00Z",
+    "last_edited_time": "2024-01-02T15:30:00Z"
+  },
+  "blocks": [
+    {
+      "id": "block-1",
+      "type": "bulleted_list_item",
+      "text": [
+        {
+          "type": "text",
+          "text": "Item"
+        }
+      ],
+      "children": [
+        {
+          "id": "block-2",
+          "type": "paragraph",
+          "text": [
+            {
+              "type": "text",
+              "text": "Test block"
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+
+	var tree Tree
+	if err := json.Unmarshal([]byte(doc.Content), &tree); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if got := NewRenderer().Render(tree.Blocks); got != "- Item\nTest block\n\n" {
+		t.Errorf("Expected the decoded tree to render, got %q", got)
+	}
+}
+
+func TestConverterJSONEmptyPage(t *testing.T) {
+	doc := (&Converter{opts: Options{Format: FormatJSON}}).mustRender(t, PageInfo{}, nil)
+
+	if !strings.Contains(doc.Content, `"blocks": []`) {
+		t.Errorf("Expected an empty blocks array, got %q", doc.Content)
+	}
+}
@@ -0,0 +1,186 @@
+package notiontomd
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// MDXComponent is the JSX component a block is written as. Prop values may
+// contain placeholders that are replaced with values of the block:
+// {title} (toggle text), {text} (callout text), {level} ("info", "warn" or
+// "alert", see the icon of a callout), {icon}, {url} and {caption}. Props
+// that are empty after replacement are left out.
+type MDXComponent struct {
+	Name  string            `json:"name"`
+	Props map[string]string `json:"props,omitempty"`
+}
+
+// MDXComponents maps blocks to JSX components. A nil component writes the
+// block as in Markdown.
+type MDXComponents struct {
+	Callout    *MDXComponent `json:"callout"`
+	Toggle     *MDXComponent `json:"toggle"`
+	Bookmark   *MDXComponent `json:"bookmark"`
+	Embed      *MDXComponent `json:"embed"`
+	Video      *MDXComponent `json:"video"`
+	ColumnList *MDXComponent `json:"column_list"`
+	Column     *MDXComponent `json:"column"`
+}
+
+// DefaultMDXComponents returns the components used when none are configured
+func DefaultMDXComponents() *MDXComponents {
+	return &MDXComponents{
+		Callout:    &MDXComponent{Name: "Callout", Props: map[string]string{"type": "{level}", "icon": "{icon}"}},
+		Toggle:     &MDXComponent{Name: "Details", Props: map[string]string{"summary": "{title}"}},
+		Bookmark:   &MDXComponent{Name: "Bookmark", Props: map[string]string{"url": "{url}", "caption": "{caption}"}},
+		Embed:      &MDXComponent{Name: "Embed", Props: map[string]string{"url": "{url}", "caption": "{caption}"}},
+		Video:      &MDXComponent{Name: "Video", Props: map[string]string{"url": "{url}", "caption": "{caption}"}},
+		ColumnList: &MDXComponent{Name: "Columns"},
+		Column:     &MDXComponent{Name: "Column"},
+	}
+}
+
+// ParseMDXComponents reads a JSON component mapping. Blocks missing from
+// the mapping keep their default component; null writes them as Markdown.
+func ParseMDXComponents(data []byte) (*MDXComponents, error) {
+	var mapping map[string]json.RawMessage
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse MDX components: %w", err)
+	}
+
+	components := DefaultMDXComponents()
+	fields := map[string]**MDXComponent{
+		"callout":     &components.Callout,
+		"toggle":      &components.Toggle,
+		"bookmark":    &components.Bookmark,
+		"embed":       &components.Embed,
+		"video":       &components.Video,
+		"column_list": &components.ColumnList,
+		"column":      &components.Column,
+	}
+	for key, raw := range mapping {
+		field, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("failed to parse MDX components: unknown block %q", key)
+		}
+		// Replace the default instead of merging into it
+		var component *MDXComponent
+		if err := json.Unmarshal(raw, &component); err != nil {
+			return nil, fmt.Errorf("failed to parse MDX components: %s: %w", key, err)
+		}
+		if component != nil && component.Name == "" {
+			return nil, fmt.Errorf("failed to parse MDX components: %s: missing component name", key)
+		}
+		*field = component
+	}
+	return components, nil
+}
+
+// NewMDXRenderer creates a Markdown renderer that writes MDX: rich text is
+// escaped and the blocks in components become JSX elements. Nil uses
+// DefaultMDXComponents.
+func NewMDXRenderer(components *MDXComponents) *Renderer {
+	if components == nil {
+		components = DefaultMDXComponents()
+	}
+
+	r := NewRenderer()
+	r.SetRichText(FormatRichTextMDX)
+	// Code is not parsed by MDX, so it must not be escaped
+	r.Register(notionapi.BlockTypeCode, func(r *Renderer, node *Node, depth int) string {
+		return "```" + node.Language + "\n" + PlainText(node.Text) + "\n```\n\n"
+	})
+	// JSX requires void elements to be self-closing
+	r.Register(notionapi.BlockTypeHeading1, renderHeading("# ", "<br />"))
+	r.Register(notionapi.BlockTypeHeading2, renderHeading("## ", "<br />"))
+	r.Register(notionapi.BlockTypeHeading3, renderHeading("### ", "<br />"))
+
+	register := func(blockType notionapi.BlockType, component *MDXComponent) {
+		if component != nil {
+			r.Register(blockType, renderMDXComponent(component))
+		}
+	}
+	register(notionapi.BlockTypeCallout, components.Callout)
+	register(notionapi.BlockTypeToggle, components.Toggle)
+	register(notionapi.BlockTypeBookmark, components.Bookmark)
+	register(notionapi.BlockTypeEmbed, components.Embed)
+	register(notionapi.BlockTypeVideo, components.Video)
+	register(notionapi.BlockTypeColumnList, components.ColumnList)
+	register(notionapi.BlockTypeColumn, components.Column)
+
+	return r
+}
+
+// renderMDXComponent returns a renderer writing blocks as the component.
+// The text of callouts and the nested blocks become its children.
+func renderMDXComponent(component *MDXComponent) BlockRenderer {
+	return func(r *Renderer, node *Node, depth int) string {
+		var children string
+		if node.Type == notionapi.BlockTypeCallout {
+			if text := r.RichText(node.Text); text != "" {
+				children = text + "\n\n"
+			}
+		}
+		children += blankLineAfter(r.Children(node, 0))
+
+		tag := "<" + component.Name + mdxProps(component.Props, node)
+		if children == "" {
+			return tag + " />\n\n"
+		}
+		return tag + ">\n\n" + children + "</" + component.Name + ">\n\n"
+	}
+}
+
+// mdxProps formats the props of a component for a block, sorted by name
+func mdxProps(props map[string]string, node *Node) string {
+	level := ""
+	if node.Type == notionapi.BlockTypeCallout {
+		level = calloutLevel(node)
+	}
+	replacer := strings.NewReplacer(
+		"{title}", PlainText(node.Text),
+		"{text}", PlainText(node.Text),
+		"{level}", level,
+		"{icon}", node.Icon,
+		"{url}", node.URL,
+		"{caption}", PlainText(node.Caption),
+	)
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result strings.Builder
+	for _, name := range names {
+		value := replacer.Replace(props[name])
+		if value == "" {
+			continue
+		}
+		// JSX string attributes support HTML character references
+		result.WriteString(" " + name + "=\"" + html.EscapeString(value) + "\"")
+	}
+	return result.String()
+}
+
+// mdxEscaper escapes characters that start JSX or JavaScript expressions in MDX
+var mdxEscaper = strings.NewReplacer("{", "\\{", "}", "\\}", "<", "\\<")
+
+// FormatRichTextMDX converts rich text to MDX. It is FormatRichText with
+// { } and < escaped outside of inline code.
+func FormatRichTextMDX(spans []Span) string {
+	escaped := make([]Span, len(spans))
+	for i, span := range spans {
+		if !span.Code {
+			span.Text = mdxEscaper.Replace(span.Text)
+		}
+		escaped[i] = span
+	}
+	return FormatRichText(escaped)
+}
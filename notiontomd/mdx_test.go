@@ -0,0 +1,85 @@
+package notiontomd
+
+import (
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestFormatRichTextMDX(t *testing.T) {
+	spans := []Span{
+		{Type: "text", Text: "Use {props} and <Tag> "},
+		{Type: "text", Text: "{code}", Code: true},
+	}
+
+	result := FormatRichTextMDX(spans)
+
+	expected := "Use \\{props\\} and \\<Tag> `{code}`"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConverterMDX(t *testing.T) {
+	warning := createCalloutBlock("Careful")
+	warning.(*notionapi.CalloutBlock).Callout.Icon = &notionapi.Icon{Type: "emoji", Emoji: ptrEmoji("⚠️")}
+
+	blocks := []BlockWithIndent{
+		{Block: warning, Indent: 0},
+		{Block: createToggleBlock(`Say "more"`), Indent: 0},
+		{Block: createTextParagraphBlock("a < b"), Indent: 1},
+		{Block: createBookmarkBlock("https://example.com"), Indent: 0},
+	}
+
+	doc := (&Converter{opts: Options{Format: FormatMDX, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+	expected := "<Callout icon=\"⚠️\" type=\"warn\">\n\nCareful\n\n</Callout>\n\n" +
+		"<Details summary=\"Say &#34;more&#34;\">\n\na \\< b\n\n</Details>\n\n" +
+		"<Bookmark url=\"https://example.com\" />\n\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+}
+
+func TestConverterMDXMultiLineHeading(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createHeading1Block("First\nSecond"), Indent: 0},
+	}
+
+	doc := (&Converter{opts: Options{Format: FormatMDX, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+	expected := "# First<br />Second\n\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+}
+
+func TestParseMDXComponents(t *testing.T) {
+	components, err := ParseMDXComponents([]byte(`{
+  "callout": {"name": "Admonition", "props": {"kind": "{level}"}},
+  "toggle": null
+}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	blocks := []BlockWithIndent{
+		{Block: createCalloutBlock("Note"), Indent: 0},
+		{Block: createToggleBlock("More"), Indent: 0},
+		{Block: createBulletedListBlock("block-1", "Hidden", false), Indent: 1},
+	}
+	doc := (&Converter{opts: Options{Format: FormatMDX, MDXComponents: components, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+	expected := "<Admonition kind=\"info\">\n\nNote\n\n</Admonition>\n\n" +
+		"- More\n  - Hidden\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+	if components.Bookmark == nil || components.Bookmark.Name != "Bookmark" {
+		t.Errorf("Expected the default bookmark component, got %+v", components.Bookmark)
+	}
+
+	if _, err := ParseMDXComponents([]byte(`{"callout": {"props": {}}}`)); err == nil {
+		t.Error("Expected an error for a component without a name")
+	}
+}
@@ -0,0 +1,280 @@
+// Package notiontomd converts Notion pages to Markdown, MDX, HTML, Org-mode,
+// AsciiDoc, plain text and JSON.
+//
+// A Converter fetches a page and its blocks through the Notion API and
+// renders them:
+//
+//	client := notionapi.NewClient(notionapi.Token(token))
+//	conv := notiontomd.New(client, notiontomd.Options{MaxDepth: notiontomd.DefaultMaxDepth})
+//	doc, err := conv.ConvertPage(ctx, "https://www.notion.so/workspace/Page-cec1568190834e1fa0ae72d268507aab")
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(doc.Content)
+//
+// Every format is rendered from the same intermediate representation: a
+// Tree of Nodes with normalized rich text (Spans), built by NewTree from the
+// blocks returned by FetchAllBlocks. The JSON format writes the Tree as is.
+//
+// The lower-level functions FetchAllBlocks, NewNodes, Convert, ConvertHTML
+// and GenerateFrontMatter can be used to work with already fetched blocks.
+package notiontomd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jomei/notionapi"
+)
+
+// Format is an output format of a Converter
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatOrg      Format = "org"
+	FormatAsciiDoc Format = "asciidoc"
+	FormatText     Format = "text"
+	// FormatJSON writes the intermediate representation as indented JSON
+	FormatJSON Format = "json"
+	// FormatMDX writes Markdown with JSX components, see NewMDXRenderer
+	FormatMDX Format = "mdx"
+)
+
+// Options configures a Converter. The zero value fetches blocks without a
+// depth limit, includes the front matter and does not cache.
+type Options struct {
+	// Format selects the output format. Empty means Markdown.
+	Format Format
+	// MaxDepth is the deepest indent level that is fetched. Zero means unlimited.
+	MaxDepth int
+	// Truncate omits blocks nested deeper than MaxDepth instead of failing
+	Truncate bool
+	// OnTruncate is called for each block whose children were omitted
+	OnTruncate func(blockID notionapi.BlockID, depth int)
+	// OmitFrontMatter leaves the metadata header out of Markdown, Org-mode,
+	// AsciiDoc and plain text and produces an HTML fragment instead of a
+	// standalone document
+	OmitFrontMatter bool
+	// HTMLStyle embeds minimal CSS in standalone HTML documents
+	HTMLStyle bool
+	// LinkURLs appends the target of links in parentheses to plain-text output
+	LinkURLs bool
+	// Cache stores fetched blocks on disk when set
+	Cache *BlockCache
+	// Renderer converts the block tree to Markdown. Nil uses NewRenderer.
+	// It is not used for other formats.
+	Renderer *Renderer
+	// Preset adapts Markdown output to a static site generator or blog. Its
+	// block renderers are only added when Renderer is nil.
+	Preset *Preset
+	// PropertyNames overrides DefaultPropertyNames for the preset's front matter
+	PropertyNames map[string]string
+	// MDXComponents maps blocks to JSX components in MDX output. Nil uses
+	// DefaultMDXComponents.
+	MDXComponents *MDXComponents
+	// ResolveLinkTitles fetches the titles of the pages that link_to_page
+	// blocks point to and stores them in Node.Title
+	ResolveLinkTitles bool
+}
+
+// Document is a converted page
+type Document struct {
+	Info   PageInfo
+	Format Format
+	// Blocks are the fetched blocks the document was rendered from
+	Blocks []BlockWithIndent
+	// Nodes are the blocks as a tree in the intermediate representation
+	Nodes []*Node
+	// FrontMatter is the metadata header: YAML, or the preset's front matter,
+	// for Markdown, YAML for MDX, #+ keywords for Org-mode, the document
+	// header for AsciiDoc and the title and URL for plain text. It is empty
+	// for other formats and when Options.OmitFrontMatter is set.
+	FrontMatter string
+	// Body is the rendered page content without metadata
+	Body string
+	// Content is the complete output, e.g. the front matter followed by the
+	// body for Markdown or a standalone document for HTML
+	Content string
+}
+
+// Converter fetches Notion pages and converts them to the configured format
+type Converter struct {
+	pages  PageFetcher
+	blocks BlockFetcher
+	opts   Options
+}
+
+// New creates a Converter using the given Notion client
+func New(client *notionapi.Client, opts Options) *Converter {
+	return &Converter{
+		pages:  client.Page,
+		blocks: client.Block,
+		opts:   opts,
+	}
+}
+
+// FetchOptions returns the options used to fetch the block tree
+func (c *Converter) FetchOptions() FetchOptions {
+	return FetchOptions{
+		MaxDepth:   c.opts.MaxDepth,
+		Truncate:   c.opts.Truncate,
+		OnTruncate: c.opts.OnTruncate,
+	}
+}
+
+// PageInfo fetches the metadata of a page given by ID or URL
+func (c *Converter) PageInfo(ctx context.Context, id string) (PageInfo, error) {
+	pageID, err := ExtractBlockID(id)
+	if err != nil {
+		return PageInfo{}, err
+	}
+	return FetchPageInfo(ctx, c.pages, notionapi.PageID(pageID))
+}
+
+// ConvertPage fetches and converts a page given by ID or URL
+func (c *Converter) ConvertPage(ctx context.Context, id string) (Document, error) {
+	info, err := c.PageInfo(ctx, id)
+	if err != nil {
+		return Document{}, err
+	}
+	return c.ConvertPageInfo(ctx, info)
+}
+
+// ConvertPageInfo fetches and converts the blocks of a page whose metadata
+// is already known, e.g. from a database query
+func (c *Converter) ConvertPageInfo(ctx context.Context, info PageInfo) (Document, error) {
+	blocks, err := c.FetchBlocks(ctx, info)
+	if err != nil {
+		return Document{}, err
+	}
+	return c.ConvertBlocks(ctx, info, blocks)
+}
+
+// FetchBlocks fetches the blocks of a page whose metadata is already known
+func (c *Converter) FetchBlocks(ctx context.Context, info PageInfo) ([]BlockWithIndent, error) {
+	fetcher := c.opts.Cache.Fetcher(c.blocks, info.LastEditedTime)
+	return FetchAllBlocks(ctx, fetcher, info.ID, c.FetchOptions())
+}
+
+// ConvertBlocks converts already fetched blocks of a page. Unlike Render,
+// it fetches the titles of linked pages when Options.ResolveLinkTitles is set.
+func (c *Converter) ConvertBlocks(ctx context.Context, info PageInfo, blocks []BlockWithIndent) (Document, error) {
+	tree := NewTree(info, blocks)
+	if c.opts.ResolveLinkTitles {
+		c.resolveLinkTitles(ctx, tree.Blocks, make(map[string]string))
+	}
+	return c.renderTree(tree, blocks)
+}
+
+// resolveLinkTitles sets the title of link_to_page nodes. Titles are cached
+// in titles by page ID. Pages that cannot be fetched, e.g. databases or
+// pages not shared with the integration, keep an empty title.
+func (c *Converter) resolveLinkTitles(ctx context.Context, nodes []*Node, titles map[string]string) {
+	for _, node := range nodes {
+		if node.Type == notionapi.BlockTypeLinkToPage && node.PageID != "" {
+			title, ok := titles[node.PageID]
+			if !ok {
+				if info, err := FetchPageInfo(ctx, c.pages, notionapi.PageID(node.PageID)); err == nil {
+					title = info.Title
+				}
+				titles[node.PageID] = title
+			}
+			node.Title = title
+		}
+		c.resolveLinkTitles(ctx, node.Children, titles)
+	}
+}
+
+// Render converts already fetched blocks of a page. It fails only for JSON
+// output of values that JSON cannot represent, e.g. a NaN number property.
+func (c *Converter) Render(info PageInfo, blocks []BlockWithIndent) (Document, error) {
+	return c.renderTree(NewTree(info, blocks), blocks)
+}
+
+// renderTree converts the tree built from blocks
+func (c *Converter) renderTree(tree *Tree, blocks []BlockWithIndent) (Document, error) {
+	info := tree.Page
+	doc := Document{
+		Info:   info,
+		Format: c.opts.Format,
+		Blocks: blocks,
+		Nodes:  tree.Blocks,
+	}
+
+	switch c.opts.Format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(tree, "", "  ")
+		if err != nil {
+			return Document{}, fmt.Errorf("failed to encode page %s as JSON: %w", info.ID, err)
+		}
+		doc.Body = string(data) + "\n"
+		doc.Content = doc.Body
+	case FormatOrg:
+		doc.Body = RenderOrg(tree.Blocks)
+		if !c.opts.OmitFrontMatter {
+			doc.FrontMatter = OrgHeader(info)
+		}
+		doc.Content = doc.FrontMatter + doc.Body
+	case FormatAsciiDoc:
+		doc.Body = RenderAsciiDoc(tree.Blocks)
+		if !c.opts.OmitFrontMatter {
+			doc.FrontMatter = AsciiDocHeader(info)
+		}
+		doc.Content = doc.FrontMatter + doc.Body
+	case FormatText:
+		doc.Body = NewTextRenderer(c.opts.LinkURLs).Render(tree.Blocks)
+		if !c.opts.OmitFrontMatter {
+			doc.FrontMatter = TextHeader(info)
+		}
+		doc.Content = doc.FrontMatter + doc.Body
+	case FormatMDX:
+		doc.Body = NewMDXRenderer(c.opts.MDXComponents).Render(tree.Blocks)
+		if !c.opts.OmitFrontMatter {
+			doc.FrontMatter = GenerateFrontMatter(info)
+		}
+		doc.Content = doc.FrontMatter + doc.Body
+	case FormatHTML:
+		doc.Body = RenderHTML(tree.Blocks)
+		doc.Content = doc.Body
+		if !c.opts.OmitFrontMatter {
+			doc.Content = HTMLDocument(info, doc.Body, c.opts.HTMLStyle)
+		}
+	default:
+		doc.Format = FormatMarkdown
+		preset := c.opts.Preset
+		renderer := c.opts.Renderer
+		if renderer == nil {
+			renderer = NewRenderer()
+			if preset != nil {
+				preset.Register(renderer)
+			}
+		}
+		nodes := tree.Blocks
+		if preset != nil && preset.Prepare != nil {
+			nodes = preset.Prepare(nodes)
+		}
+		doc.Body = renderer.Render(nodes)
+		switch {
+		case preset != nil && preset.Document != nil:
+			doc.Content = doc.Body
+			if !c.opts.OmitFrontMatter {
+				doc.Content = preset.Document(NewSiteMeta(info, c.opts.PropertyNames), doc.Body)
+			}
+		case preset != nil:
+			if !c.opts.OmitFrontMatter {
+				doc.FrontMatter = preset.FrontMatter(NewSiteMeta(info, c.opts.PropertyNames))
+			}
+			doc.Content = doc.FrontMatter + doc.Body
+		default:
+			if !c.opts.OmitFrontMatter {
+				doc.FrontMatter = GenerateFrontMatter(info)
+			}
+			doc.Content = doc.FrontMatter + doc.Body
+		}
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,165 @@
+package notiontomd
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// mockPageFetcher is a mock implementation of PageFetcher for testing
+type mockPageFetcher struct {
+	page *notionapi.Page
+	err  error
+}
+
+func (m *mockPageFetcher) Get(ctx context.Context, pageID notionapi.PageID) (*notionapi.Page, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.page, nil
+}
+
+// Helper function to create a page with a title property
+func createPage(id string, title string) *notionapi.Page {
+	return &notionapi.Page{
+		Object:         "page",
+		ID:             notionapi.ObjectID(id),
+		URL:            "https://www.notion.so/test-page",
+		CreatedTime:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		LastEditedTime: time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC),
+		Properties: notionapi.Properties{
+			"Name": &notionapi.TitleProperty{
+				Title: []notionapi.RichText{{PlainText: title}},
+			},
+		},
+	}
+}
+
+func TestConverterConvertPage(t *testing.T) {
+	ctx := context.Background()
+	conv := &Converter{
+		pages: &mockPageFetcher{page: createPage("cec15681-9083-4e1f-a0ae-72d268507aab", "Test Page Title")},
+		blocks: &mockBlockFetcher{
+			responses: []*notionapi.GetChildrenResponse{
+				{
+					Results: []notionapi.Block{createBulletedListBlock("block-1", "Item", false)},
+					HasMore: false,
+				},
+			},
+		},
+	}
+
+	doc, err := conv.ConvertPage(ctx, "https://www.notion.so/workspace/Page-cec1568190834e1fa0ae72d268507aab")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if doc.Info.Title != "Test Page Title" {
+		t.Errorf("Expected title %q, got %q", "Test Page Title", doc.Info.Title)
+	}
+	if doc.Info.ID != "cec15681-9083-4e1f-a0ae-72d268507aab" {
+		t.Errorf("Expected ID %q, got %q", "cec15681-9083-4e1f-a0ae-72d268507aab", doc.Info.ID)
+	}
+
+	expected := "---\n" +
+		"title: \"Test Page Title\"\n" +
+		"url: https://www.notion.so/test-page\n" +
+		"created: 2024-01-01T12:00:00Z\n" +
+		"updated: 2024-01-02T15:30:00Z\n" +
+		"---\n\n" +
+		"- Item\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+}
+
+// Helper function to render blocks, failing the test on errors
+func (c *Converter) mustRender(t *testing.T, info PageInfo, blocks []BlockWithIndent) Document {
+	t.Helper()
+	doc, err := c.Render(info, blocks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return doc
+}
+
+func TestConverterJSONError(t *testing.T) {
+	nan := math.NaN()
+	info := PageInfo{ID: "page-1", Properties: map[string]Property{"Score": {Type: "number", Number: &nan}}}
+
+	if _, err := (&Converter{opts: Options{Format: FormatJSON}}).Render(info, nil); err == nil {
+		t.Error("Expected an error for a value JSON cannot represent")
+	}
+}
+
+func TestConverterOmitFrontMatter(t *testing.T) {
+	conv := &Converter{opts: Options{OmitFrontMatter: true}}
+	blocks := []BlockWithIndent{
+		{Block: createBulletedListBlock("block-1", "Item", false), Indent: 0},
+	}
+
+	doc := conv.mustRender(t, PageInfo{Title: "Ignored"}, blocks)
+
+	if doc.FrontMatter != "" {
+		t.Errorf("Expected no front matter, got %q", doc.FrontMatter)
+	}
+	if doc.Content != "- Item\n" {
+		t.Errorf("Expected %q, got %q", "- Item\n", doc.Content)
+	}
+}
+
+func TestConverterCustomRenderer(t *testing.T) {
+	renderer := NewRenderer()
+	renderer.Register(notionapi.BlockTypeBulletedListItem, func(r *Renderer, node *Node, depth int) string {
+		return Indent(depth) + "* " + r.RichText(node.Text) + "\n" + r.Children(node, depth+1)
+	})
+	conv := &Converter{opts: Options{OmitFrontMatter: true, Renderer: renderer}}
+	blocks := []BlockWithIndent{
+		{Block: createBulletedListBlock("block-1", "Item", false), Indent: 0},
+	}
+
+	doc := conv.mustRender(t, PageInfo{}, blocks)
+
+	if doc.Body != "* Item\n" {
+		t.Errorf("Expected %q, got %q", "* Item\n", doc.Body)
+	}
+}
+
+func TestConverterHTML(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createParagraphBlock("block-1", false), Indent: 0},
+	}
+
+	fragment := (&Converter{opts: Options{Format: FormatHTML, OmitFrontMatter: true}}).mustRender(t, PageInfo{Title: "Page"}, blocks)
+	if fragment.Content != "<p>Test block</p>\n" {
+		t.Errorf("Expected %q, got %q", "<p>Test block</p>\n", fragment.Content)
+	}
+	if fragment.FrontMatter != "" {
+		t.Errorf("Expected no front matter, got %q", fragment.FrontMatter)
+	}
+
+	doc := (&Converter{opts: Options{Format: FormatHTML}}).mustRender(t, PageInfo{Title: "Page"}, blocks)
+	if !strings.HasPrefix(doc.Content, "<!DOCTYPE html>\n") || !strings.Contains(doc.Content, "<p>Test block</p>\n</body>") {
+		t.Errorf("Expected a standalone document, got %q", doc.Content)
+	}
+	if doc.Body != "<p>Test block</p>\n" {
+		t.Errorf("Expected %q, got %q", "<p>Test block</p>\n", doc.Body)
+	}
+}
+
+func TestConverterConvertPageError(t *testing.T) {
+	ctx := context.Background()
+	conv := &Converter{
+		pages:  &mockPageFetcher{err: errors.New("API error")},
+		blocks: &mockBlockFetcher{},
+	}
+
+	if _, err := conv.ConvertPage(ctx, "cec15681-9083-4e1f-a0ae-72d268507aab"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
@@ -0,0 +1,266 @@
+package notiontomd
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// blockTypeAudio is the type of audio blocks, which notionapi has no constant for
+const blockTypeAudio notionapi.BlockType = "audio"
+
+// ObsidianOptions configures NewObsidian
+type ObsidianOptions struct {
+	// LinkTarget returns the note name a wikilink to a page points to, i.e.
+	// the file name the page is exported to without extension. Nil uses the
+	// page title, or the page ID when the title is unknown.
+	LinkTarget func(pageID, title string) string
+}
+
+// Obsidian is the Obsidian preset with wikilinks to page titles
+var Obsidian = NewObsidian(ObsidianOptions{})
+
+// NewObsidian creates a preset for Obsidian vaults. Page mentions, child
+// pages and links to pages become wikilinks, callouts and toggles become
+// Obsidian callouts and attachments are embedded by file name (see
+// Attachments). Set Options.ResolveLinkTitles so that links to pages point
+// to their titles instead of their IDs.
+func NewObsidian(opts ObsidianOptions) *Preset {
+	target := opts.LinkTarget
+	if target == nil {
+		target = func(pageID, title string) string {
+			if title == "" {
+				return pageID
+			}
+			return title
+		}
+	}
+
+	return &Preset{
+		Name:        "obsidian",
+		FrontMatter: obsidianFrontMatter,
+		Register: func(r *Renderer) {
+			r.SetRichText(func(spans []Span) string {
+				return formatRichTextObsidian(spans, target)
+			})
+			r.Register(notionapi.BlockTypeCallout, func(r *Renderer, node *Node, depth int) string {
+				kind := map[string]string{"info": "note", "warn": "warning", "alert": "danger"}[calloutLevel(node)]
+				return renderObsidianCallout(r, node, "[!"+kind+"]", r.RichText(node.Text))
+			})
+			r.Register(notionapi.BlockTypeToggle, func(r *Renderer, node *Node, depth int) string {
+				// A collapsed callout with the toggle text as its title
+				title := joinLines(r.RichText(node.Text), " ")
+				return renderObsidianCallout(r, node, "[!note]- "+title, "")
+			})
+			r.Register(notionapi.BlockTypeChildPage, func(r *Renderer, node *Node, depth int) string {
+				return wikilink(target(node.ID, node.Title), node.Title) + "\n\n"
+			})
+			r.Register(notionapi.BlockTypeLinkToPage, func(r *Renderer, node *Node, depth int) string {
+				return wikilink(target(node.PageID, node.Title), node.Title) + "\n\n"
+			})
+			for _, blockType := range []notionapi.BlockType{
+				notionapi.BlockTypeImage,
+				notionapi.BlockTypeVideo,
+				blockTypeAudio,
+				notionapi.BlockTypeFile,
+				notionapi.BlockTypePdf,
+			} {
+				r.Register(blockType, renderObsidianAttachment)
+			}
+		},
+	}
+}
+
+// obsidianFrontMatter writes the page metadata as Obsidian properties
+func obsidianFrontMatter(meta SiteMeta) string {
+	var result strings.Builder
+
+	result.WriteString("---\n")
+	result.WriteString("title: " + quoteString(meta.Title) + "\n")
+	result.WriteString("url: " + meta.Info.URL + "\n")
+	result.WriteString("created: " + meta.Info.CreatedTime.Format(time.RFC3339) + "\n")
+	result.WriteString("updated: " + meta.LastMod.Format(time.RFC3339) + "\n")
+	if len(meta.Tags) > 0 {
+		result.WriteString("tags:\n")
+		for _, tag := range meta.Tags {
+			result.WriteString("  - " + quoteString(obsidianTag(tag)) + "\n")
+		}
+	}
+	if len(meta.Aliases) > 0 {
+		result.WriteString("aliases:\n")
+		for _, alias := range meta.Aliases {
+			result.WriteString("  - " + quoteString(alias) + "\n")
+		}
+	}
+	result.WriteString("---\n\n")
+
+	return result.String()
+}
+
+// obsidianTag turns a tag into one Obsidian accepts, which cannot contain
+// spaces or start with #
+func obsidianTag(tag string) string {
+	return strings.Join(strings.Fields(strings.TrimPrefix(tag, "#")), "-")
+}
+
+// formatRichTextObsidian converts rich text to Markdown with page mentions
+// as wikilinks
+func formatRichTextObsidian(spans []Span, target func(pageID, title string) string) string {
+	var result strings.Builder
+
+	for _, span := range spans {
+		if m := span.Mention; m != nil && (m.Type == "page" || m.Type == "database") {
+			result.WriteString(wikilink(target(m.ID, span.Text), span.Text))
+			continue
+		}
+		result.WriteString(FormatRichText([]Span{span}))
+	}
+
+	return result.String()
+}
+
+// wikilink returns [[target]], or [[target|text]] when the text differs
+func wikilink(target, text string) string {
+	if text == "" || text == target {
+		return "[[" + target + "]]"
+	}
+	return "[[" + target + "|" + text + "]]"
+}
+
+// renderObsidianCallout writes a callout whose text and nested blocks are
+// all inside the quote
+func renderObsidianCallout(r *Renderer, node *Node, header, text string) string {
+	body := header
+	text = joinLines(text, "\n")
+	if text != "" {
+		body += "\n" + text
+	}
+	if children := strings.TrimRight(r.Children(node, 0), "\n"); children != "" {
+		// A blank line keeps a nested paragraph from joining the text
+		if text != "" {
+			body += "\n"
+		}
+		body += "\n" + children
+	}
+	return quoteLines(body) + "\n"
+}
+
+// quoteLines prefixes every line with "> ", or ">" for blank lines
+func quoteLines(text string) string {
+	var result strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			result.WriteString(">\n")
+		} else {
+			result.WriteString("> " + line + "\n")
+		}
+	}
+	return result.String()
+}
+
+// renderObsidianAttachment embeds a downloaded attachment, or links to the
+// URL when the block does not point to a file
+func renderObsidianAttachment(r *Renderer, node *Node, depth int) string {
+	caption := r.RichText(node.Caption)
+
+	var result string
+	switch {
+	case node.URL == "":
+		return ""
+	case isAttachment(node):
+		result = "![[" + AttachmentName(node) + "]]"
+	case node.Type == notionapi.BlockTypeImage || node.Type == notionapi.BlockTypeVideo:
+		// Obsidian embeds external images and videos such as YouTube links
+		result = "![" + PlainText(node.Caption) + "](" + node.URL + ")"
+	default:
+		if caption == "" {
+			caption = node.URL
+		}
+		return "[" + caption + "](" + node.URL + ")\n\n"
+	}
+
+	if caption != "" {
+		result += "\n" + caption
+	}
+	return result + "\n\n"
+}
+
+// Attachment is a file referenced by a page
+type Attachment struct {
+	// URL is where the file is downloaded from. URLs of files uploaded to
+	// Notion expire after an hour.
+	URL string
+	// Name is the file name the page refers to the attachment by
+	Name string
+}
+
+// Attachments returns the files embedded by image, video, audio, file and
+// pdf blocks, in document order
+func Attachments(nodes []*Node) []Attachment {
+	var attachments []Attachment
+	for _, node := range nodes {
+		if isAttachment(node) {
+			attachments = append(attachments, Attachment{URL: node.URL, Name: AttachmentName(node)})
+		}
+		attachments = append(attachments, Attachments(node.Children)...)
+	}
+	return attachments
+}
+
+// isAttachment reports whether the block embeds a file that can be
+// downloaded. URLs without a file extension, such as YouTube links, are
+// pages rather than files.
+func isAttachment(node *Node) bool {
+	switch node.Type {
+	case notionapi.BlockTypeImage, notionapi.BlockTypeVideo, blockTypeAudio,
+		notionapi.BlockTypeFile, notionapi.BlockTypePdf:
+	default:
+		return false
+	}
+	u, err := url.Parse(node.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return path.Ext(u.Path) != ""
+}
+
+// AttachmentName returns a file name for an attachment that is unique in
+// the vault: the file name from the URL with the start of the block ID
+// appended, e.g. "diagram-0c1d2e3f.png"
+func AttachmentName(node *Node) string {
+	base := "attachment"
+	ext := ""
+	if u, err := url.Parse(node.URL); err == nil {
+		name := path.Base(u.Path)
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			name = unescaped
+		}
+		ext = path.Ext(name)
+		if stem := sanitizeFileName(strings.TrimSuffix(name, ext)); stem != "" && stem != "." && stem != "/" {
+			base = stem
+		}
+	}
+
+	id := strings.ReplaceAll(node.ID, "-", "")
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	if id != "" {
+		base += "-" + id
+	}
+	return base + sanitizeFileName(ext)
+}
+
+// sanitizeFileName removes characters that break wikilinks or file paths
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '#', '^', '[', ']', '|', '*', '?', '"', '<', '>':
+			return -1
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,175 @@
+package notiontomd
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// Helper function to create a paragraph mentioning a page
+func createMentionParagraphBlock(pageID string, title string) notionapi.Block {
+	return &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			Type:   notionapi.BlockTypeParagraph,
+		},
+		Paragraph: notionapi.Paragraph{
+			RichText: []notionapi.RichText{
+				{Type: "text", PlainText: "See "},
+				{
+					Type:      "mention",
+					PlainText: title,
+					Mention: &notionapi.Mention{
+						Type: notionapi.MentionTypePage,
+						Page: &notionapi.PageMention{ID: notionapi.ObjectID(pageID)},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Helper function to create an image block with an external URL
+func createImageBlock(id string, url string) notionapi.Block {
+	return &notionapi.ImageBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     notionapi.BlockID(id),
+			Type:   notionapi.BlockTypeImage,
+		},
+		Image: notionapi.Image{
+			Type:     notionapi.FileTypeExternal,
+			External: &notionapi.FileObject{URL: url},
+		},
+	}
+}
+
+func TestObsidianBlocks(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createMentionParagraphBlock("page-2", "Other page"), Indent: 0},
+		{Block: createCalloutBlock("Heads up"), Indent: 0},
+		{Block: createToggleBlock("More"), Indent: 0},
+		{Block: createBulletedListBlock("block-1", "Hidden", false), Indent: 1},
+		{Block: &notionapi.ChildPageBlock{
+			BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, ID: "child-1", Type: notionapi.BlockTypeChildPage},
+			ChildPage: struct {
+				Title string `json:"title"`
+			}{Title: "Child"},
+		}, Indent: 0},
+		{Block: createImageBlock("0c1d2e3f-4a5b-6c7d-8e9f-000000000000", "https://example.com/img/diagram.png?v=1"), Indent: 0},
+	}
+
+	doc := (&Converter{opts: Options{Preset: Obsidian, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+	expected := "See [[Other page]]\n\n" +
+		"> [!note]\n> Heads up\n\n" +
+		"> [!note]- More\n> - Hidden\n\n" +
+		"[[Child]]\n\n" +
+		"![[diagram-0c1d2e3f.png]]\n\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+
+	attachments := Attachments(doc.Nodes)
+	expectedAttachments := []Attachment{{URL: "https://example.com/img/diagram.png?v=1", Name: "diagram-0c1d2e3f.png"}}
+	if !reflect.DeepEqual(attachments, expectedAttachments) {
+		t.Errorf("Expected %+v, got %+v", expectedAttachments, attachments)
+	}
+}
+
+func TestObsidianLinkTarget(t *testing.T) {
+	preset := NewObsidian(ObsidianOptions{
+		LinkTarget: func(pageID, title string) string {
+			return strings.ToLower(strings.ReplaceAll(title, " ", "-"))
+		},
+	})
+	blocks := []BlockWithIndent{
+		{Block: createMentionParagraphBlock("page-2", "Other page"), Indent: 0},
+	}
+
+	doc := (&Converter{opts: Options{Preset: preset, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+	expected := "See [[other-page|Other page]]\n\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+}
+
+func TestObsidianCalloutWithChildren(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createCalloutBlock("Heads up"), Indent: 0},
+		{Block: createParagraphBlock("block-1", false), Indent: 1},
+	}
+
+	doc := (&Converter{opts: Options{Preset: Obsidian, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+	expected := "> [!note]\n> Heads up\n>\n> Test block\n\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+}
+
+func TestObsidianFrontMatter(t *testing.T) {
+	info := samplePageInfo()
+	info.Properties["Tags"] = Property{Type: "multi_select", Values: []string{"go", "web dev"}}
+	info.Properties["Aliases"] = Property{Type: "rich_text", Text: "Hello, Greeting"}
+
+	result := Obsidian.FrontMatter(NewSiteMeta(info, nil))
+
+	expected := "---\n" +
+		"title: \"Say \\\"hello\\\"\"\n" +
+		"url: https://www.notion.so/page-1\n" +
+		"created: 2024-01-01T12:00:00Z\n" +
+		"updated: 2024-01-02T15:30:00Z\n" +
+		"tags:\n  - \"go\"\n  - \"web-dev\"\n" +
+		"aliases:\n  - \"Hello\"\n  - \"Greeting\"\n" +
+		"---\n\n"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestResolveLinkTitles(t *testing.T) {
+	link := &notionapi.LinkToPageBlock{
+		BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeLinkToPage},
+		LinkToPage: notionapi.LinkToPage{Type: "page_id", PageID: "cec15681-9083-4e1f-a0ae-72d268507aab"},
+	}
+	conv := &Converter{
+		pages: &mockPageFetcher{page: createPage("cec15681-9083-4e1f-a0ae-72d268507aab", "Linked")},
+		blocks: &mockBlockFetcher{
+			responses: []*notionapi.GetChildrenResponse{{Results: []notionapi.Block{link}}},
+		},
+		opts: Options{Preset: Obsidian, OmitFrontMatter: true, ResolveLinkTitles: true},
+	}
+
+	doc, err := conv.ConvertPageInfo(context.Background(), PageInfo{ID: "page-1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "[[Linked]]\n\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+}
+
+func TestAttachmentName(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://example.com/a/My%20Photo.JPG", "My Photo-0c1d2e3f.JPG"},
+		{"https://example.com/a/what[1].png", "what1-0c1d2e3f.png"},
+		{"https://example.com/", "attachment-0c1d2e3f"},
+	}
+
+	for _, tt := range tests {
+		node := &Node{ID: "0c1d2e3f-4a5b-6c7d-8e9f-000000000000", Type: notionapi.BlockTypeImage, URL: tt.url}
+		if got := AttachmentName(node); got != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package notiontomd
+
+import (
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// orgTimestamp is the layout of inactive Org-mode timestamps
+const orgTimestamp = "[2006-01-02 Mon 15:04]"
+
+// OrgHeader generates Org-mode keywords from page metadata
+func OrgHeader(info PageInfo) string {
+	var result strings.Builder
+
+	result.WriteString("#+TITLE: " + info.Title + "\n")
+	result.WriteString("#+DATE: " + info.CreatedTime.Format(orgTimestamp) + "\n")
+	result.WriteString("#+URL: " + info.URL + "\n")
+	result.WriteString("#+UPDATED: " + info.LastEditedTime.Format(orgTimestamp) + "\n")
+	result.WriteString("\n")
+
+	return result.String()
+}
+
+// ConvertOrg converts blocks with indentation to Org-mode
+func ConvertOrg(blocks []BlockWithIndent) string {
+	return RenderOrg(NewNodes(blocks))
+}
+
+// RenderOrg converts a block tree to Org-mode
+func RenderOrg(nodes []*Node) string {
+	var result strings.Builder
+	writeOrgNodes(&result, nodes, 0)
+	return result.String()
+}
+
+// writeOrgNodes writes sibling blocks. depth is the list nesting level.
+func writeOrgNodes(w *strings.Builder, nodes []*Node, depth int) {
+	for i, node := range nodes {
+		writeOrgNode(w, node, depth)
+
+		// A blank line ends a top-level list before the next block
+		if depth == 0 && isListOrToggle(node) && (i == len(nodes)-1 || !isListOrToggle(nodes[i+1])) {
+			w.WriteString("\n")
+		}
+	}
+}
+
+// writeOrgChildren writes the nested blocks of a node and the truncation marker
+func writeOrgChildren(w *strings.Builder, node *Node, depth int) {
+	writeOrgNodes(w, node.Children, depth)
+	if node.Truncated {
+		w.WriteString(Indent(depth) + "- /(truncated: nested blocks omitted)/\n")
+	}
+}
+
+// writeOrgNode writes a single block with its nested blocks
+func writeOrgNode(w *strings.Builder, node *Node, depth int) {
+	indent := Indent(depth)
+
+	switch node.Type {
+	case notionapi.BlockTypeHeading1, notionapi.BlockTypeHeading2, notionapi.BlockTypeHeading3:
+		stars := strings.Repeat("*", headingLevel(node.Type))
+		w.WriteString(stars + " " + joinLines(FormatRichTextOrg(node.Text), " ") + "\n\n")
+		writeOrgChildren(w, node, depth)
+	case notionapi.BlockTypeParagraph:
+		if text := FormatRichTextOrg(node.Text); text != "" {
+			w.WriteString(joinLines(text, "\\\\\n") + "\n\n")
+		}
+		writeOrgChildren(w, node, depth)
+	case notionapi.BlockTypeBulletedListItem, notionapi.BlockTypeToggle:
+		text := joinLines(FormatRichTextOrg(node.Text), "\\\\\n"+indent+"  ")
+		w.WriteString(indent + "- " + text + "\n")
+		writeOrgChildren(w, node, depth+1)
+	case notionapi.BlockTypeNumberedListItem:
+		text := joinLines(FormatRichTextOrg(node.Text), "\\\\\n"+indent+"   ")
+		w.WriteString(indent + "1. " + text + "\n")
+		writeOrgChildren(w, node, depth+1)
+	case notionapi.BlockTypeToDo:
+		checkbox := "[ ] "
+		if node.Checked {
+			checkbox = "[X] "
+		}
+		text := joinLines(FormatRichTextOrg(node.Text), "\\\\\n"+indent+"  ")
+		w.WriteString(indent + "- " + checkbox + text + "\n")
+		writeOrgChildren(w, node, depth+1)
+	case notionapi.BlockTypeCode:
+		w.WriteString("#+BEGIN_SRC")
+		if lang := sourceLanguage(node.Language); lang != "" {
+			w.WriteString(" " + lang)
+		}
+		w.WriteString("\n" + PlainText(node.Text) + "\n#+END_SRC\n\n")
+	case notionapi.BlockTypeQuote:
+		w.WriteString("#+BEGIN_QUOTE\n" + joinLines(FormatRichTextOrg(node.Text), "\\\\\n") + "\n")
+		writeOrgChildren(w, node, 0)
+		w.WriteString("#+END_QUOTE\n\n")
+	case notionapi.BlockTypeCallout:
+		w.WriteString("#+BEGIN_NOTE\n" + calloutPrefix(node) + joinLines(FormatRichTextOrg(node.Text), "\\\\\n") + "\n")
+		writeOrgChildren(w, node, 0)
+		w.WriteString("#+END_NOTE\n\n")
+	case notionapi.BlockTypeDivider:
+		w.WriteString("-----\n\n")
+	default:
+		writeOrgChildren(w, node, depth)
+	}
+}
+
+// FormatRichTextOrg converts rich text to Org-mode markup
+func FormatRichTextOrg(spans []Span) string {
+	var result strings.Builder
+
+	for _, span := range spans {
+		text := span.Text
+
+		if span.Code {
+			text = "~" + text + "~"
+		}
+		if span.Bold {
+			text = "*" + text + "*"
+		}
+		if span.Italic {
+			text = "/" + text + "/"
+		}
+		if span.Strikethrough {
+			text = "+" + text + "+"
+		}
+		if span.Underline {
+			text = "_" + text + "_"
+		}
+
+		if span.Href != "" {
+			text = "[[" + span.Href + "][" + text + "]]"
+		}
+
+		result.WriteString(text)
+	}
+
+	return result.String()
+}
@@ -0,0 +1,108 @@
+package notiontomd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// sampleBlocks returns a page with every block type handled by Convert
+func sampleBlocks() []BlockWithIndent {
+	return []BlockWithIndent{
+		{Block: createHeading1Block("Title"), Indent: 0},
+		{
+			Block: &notionapi.ParagraphBlock{
+				BasicBlock: notionapi.BasicBlock{Object: "block", Type: notionapi.BlockTypeParagraph},
+				Paragraph: notionapi.Paragraph{
+					RichText: []notionapi.RichText{
+						{PlainText: "Some "},
+						{PlainText: "bold", Annotations: &notionapi.Annotations{Bold: true}},
+						{PlainText: " and "},
+						{PlainText: "code", Annotations: &notionapi.Annotations{Code: true}},
+						{PlainText: " with a "},
+						{PlainText: "link", Href: "https://example.com"},
+					},
+				},
+			},
+			Indent: 0,
+		},
+		{Block: createBulletedListBlock("block-1", "Parent", true), Indent: 0},
+		{Block: createNumberedListBlock("block-2", "Child", false), Indent: 1},
+		{Block: createBulletedListBlock("block-3", "Sibling", false), Indent: 0},
+		{
+			Block: &notionapi.CodeBlock{
+				BasicBlock: notionapi.BasicBlock{Object: "block", Type: notionapi.BlockTypeCode},
+				Code: notionapi.Code{
+					RichText: []notionapi.RichText{{PlainText: "fmt.Println(1)"}},
+					Language: "go",
+				},
+			},
+			Indent: 0,
+		},
+		{
+			Block: &notionapi.QuoteBlock{
+				BasicBlock: notionapi.BasicBlock{Object: "block", Type: notionapi.BlockTypeQuote},
+				Quote: notionapi.Quote{
+					RichText: []notionapi.RichText{{PlainText: "Quoted"}},
+				},
+			},
+			Indent: 0,
+		},
+		{Block: createCalloutBlock("Note"), Indent: 0},
+		{
+			Block:  &notionapi.DividerBlock{BasicBlock: notionapi.BasicBlock{Object: "block", Type: notionapi.BlockTypeDivider}},
+			Indent: 0,
+		},
+	}
+}
+
+func TestConvertOrg(t *testing.T) {
+	result := ConvertOrg(sampleBlocks())
+	expected := "* Title\n\n" +
+		"Some *bold* and ~code~ with a [[https://example.com][link]]\n\n" +
+		"- Parent\n" +
+		"  1. Child\n" +
+		"- Sibling\n\n" +
+		"#+BEGIN_SRC go\nfmt.Println(1)\n#+END_SRC\n\n" +
+		"#+BEGIN_QUOTE\nQuoted\n#+END_QUOTE\n\n" +
+		"#+BEGIN_NOTE\nNote\n#+END_NOTE\n\n" +
+		"-----\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertOrgMultiLineListItem(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createBulletedListBlock("block-1", "Parent", true), Indent: 0},
+		{Block: createBulletedListBlock("block-2", "line1\nline2", false), Indent: 1},
+	}
+
+	result := ConvertOrg(blocks)
+	expected := "- Parent\n  - line1\\\\\n    line2\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestOrgHeader(t *testing.T) {
+	info := PageInfo{
+		Title:          "Page",
+		URL:            "https://www.notion.so/page",
+		CreatedTime:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		LastEditedTime: time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC),
+	}
+
+	result := OrgHeader(info)
+	expected := "#+TITLE: Page\n" +
+		"#+DATE: [2024-01-01 Mon 12:00]\n" +
+		"#+URL: https://www.notion.so/page\n" +
+		"#+UPDATED: [2024-01-02 Tue 15:30]\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package notiontomd
 
 import (
 	"fmt"
@@ -8,8 +8,8 @@ import (
 	"github.com/jomei/notionapi"
 )
 
-// extractBlockID extracts block ID from a Notion URL or returns the input as-is if it's already an ID
-func extractBlockID(input string) (notionapi.BlockID, error) {
+// ExtractBlockID extracts block ID from a Notion URL or returns the input as-is if it's already an ID
+func ExtractBlockID(input string) (notionapi.BlockID, error) {
 	// If it's a URL, extract the ID
 	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
 		// Match 32-character hex string (without hyphens)
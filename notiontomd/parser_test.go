@@ -1,4 +1,4 @@
-package main
+package notiontomd
 
 import (
 	"testing"
@@ -53,7 +53,7 @@ func TestExtractBlockIDFromURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := extractBlockID(tt.input)
+			result, err := ExtractBlockID(tt.input)
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("Expected error, got nil")
@@ -0,0 +1,331 @@
+package notiontomd
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jomei/notionapi"
+)
+
+// Preset adapts Markdown output to a static site generator or publishing
+// platform: the front matter it expects and its syntax for blocks such as
+// callouts and toggles
+type Preset struct {
+	// Name identifies the preset, e.g. on the command line
+	Name string
+	// FrontMatter generates the front matter from the page metadata
+	FrontMatter func(meta SiteMeta) string
+	// Register replaces block renderers with the preset's syntax
+	Register func(r *Renderer)
+	// Prepare rewrites the block tree before it is rendered, if set
+	Prepare func(nodes []*Node) []*Node
+	// Document wraps the body into the complete output, if set. The front
+	// matter is not used then.
+	Document func(meta SiteMeta, body string) string
+}
+
+// Presets returns the built-in presets
+func Presets() []*Preset {
+	return []*Preset{Hugo, Jekyll, Docusaurus, Zola, Zenn, Qiita, Obsidian, Hatena, HatenaAtomPub, Marp, RevealJS}
+}
+
+// LookupPreset returns the built-in preset with the given name, or nil
+func LookupPreset(name string) *Preset {
+	for _, preset := range Presets() {
+		if preset.Name == name {
+			return preset
+		}
+	}
+	return nil
+}
+
+// Front matter fields that are read from page properties
+const (
+	FieldDate    = "date"
+	FieldDraft   = "draft"
+	FieldSlug    = "slug"
+	FieldTags    = "tags"
+	FieldWeight  = "weight"
+	FieldAliases = "aliases"
+)
+
+// DefaultPropertyNames maps front matter fields to the page properties they
+// are read from. Property names are matched ignoring case.
+var DefaultPropertyNames = map[string]string{
+	FieldDate:    "Date",
+	FieldDraft:   "Draft",
+	FieldSlug:    "Slug",
+	FieldTags:    "Tags",
+	FieldWeight:  "Weight",
+	FieldAliases: "Aliases",
+}
+
+// SiteMeta holds the front matter fields of a page for static site generators
+type SiteMeta struct {
+	Info  PageInfo
+	Title string
+	// Date is the date property, or the creation time of the page
+	Date time.Time
+	// LastMod is the last edited time of the page
+	LastMod time.Time
+	Draft   bool
+	// Slug is empty when the page has no slug property
+	Slug string
+	Tags []string
+	// Weight is nil when the page has no weight property
+	Weight *int
+	// Aliases are alternative names of the page
+	Aliases []string
+}
+
+// NewSiteMeta reads the front matter fields of a page. names overrides
+// entries of DefaultPropertyNames.
+func NewSiteMeta(info PageInfo, names map[string]string) SiteMeta {
+	meta := SiteMeta{
+		Info:    info,
+		Title:   info.Title,
+		Date:    info.CreatedTime,
+		LastMod: info.LastEditedTime,
+	}
+
+	property := func(field string) (Property, bool) {
+		name, ok := names[field]
+		if !ok {
+			name = DefaultPropertyNames[field]
+		}
+		if name == "" {
+			return Property{}, false
+		}
+		return info.LookupProperty(name)
+	}
+
+	if p, ok := property(FieldDate); ok && p.Date != nil {
+		meta.Date = *p.Date
+	}
+	if p, ok := property(FieldDraft); ok {
+		// A checkbox, or a select or status named "Draft"
+		meta.Draft = p.Checkbox || strings.EqualFold(p.Text, "draft")
+	}
+	if p, ok := property(FieldSlug); ok {
+		meta.Slug = p.Text
+	}
+	if p, ok := property(FieldTags); ok {
+		meta.Tags = propertyValues(p)
+	}
+	if p, ok := property(FieldWeight); ok && p.Number != nil {
+		weight := int(math.Round(*p.Number))
+		meta.Weight = &weight
+	}
+	if p, ok := property(FieldAliases); ok {
+		meta.Aliases = propertyValues(p)
+	}
+
+	return meta
+}
+
+// propertyValues returns the options of a multi-select property, or the
+// comma-separated values of a text property
+func propertyValues(p Property) []string {
+	if p.Values != nil {
+		return p.Values
+	}
+	var values []string
+	for _, value := range strings.Split(p.Text, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// quoteString quotes a string for YAML and TOML, which share the escapes
+// of double-quoted strings
+func quoteString(s string) string {
+	var result strings.Builder
+	result.WriteString("\"")
+	for _, r := range s {
+		switch r {
+		case '"':
+			result.WriteString("\\\"")
+		case '\\':
+			result.WriteString("\\\\")
+		case '\n':
+			result.WriteString("\\n")
+		case '\t':
+			result.WriteString("\\t")
+		case '\r':
+			result.WriteString("\\r")
+		default:
+			if unicode.IsControl(r) {
+				fmt.Fprintf(&result, "\\u%04X", r)
+			} else {
+				result.WriteRune(r)
+			}
+		}
+	}
+	result.WriteString("\"")
+	return result.String()
+}
+
+// quoteList formats values as a YAML flow sequence or TOML array
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quoteString(value)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// Hugo writes TOML front matter, GitHub-style alerts for callouts and the
+// details shortcode for toggles
+var Hugo = &Preset{
+	Name: "hugo",
+	FrontMatter: func(meta SiteMeta) string {
+		var result strings.Builder
+		result.WriteString("+++\n")
+		result.WriteString("title = " + quoteString(meta.Title) + "\n")
+		result.WriteString("date = " + meta.Date.Format(time.RFC3339) + "\n")
+		result.WriteString("lastmod = " + meta.LastMod.Format(time.RFC3339) + "\n")
+		fmt.Fprintf(&result, "draft = %t\n", meta.Draft)
+		if meta.Slug != "" {
+			result.WriteString("slug = " + quoteString(meta.Slug) + "\n")
+		}
+		if len(meta.Tags) > 0 {
+			result.WriteString("tags = " + quoteList(meta.Tags) + "\n")
+		}
+		if meta.Weight != nil {
+			fmt.Fprintf(&result, "weight = %d\n", *meta.Weight)
+		}
+		result.WriteString("+++\n\n")
+		return result.String()
+	},
+	Register: func(r *Renderer) {
+		r.Register(notionapi.BlockTypeCallout, renderAlertCallout)
+		r.Register(notionapi.BlockTypeToggle, func(r *Renderer, node *Node, depth int) string {
+			summary := quoteString(PlainText(node.Text))
+			return "{{< details summary=" + summary + " >}}\n\n" + blankLineAfter(r.Children(node, 0)) + "{{< /details >}}\n\n"
+		})
+	},
+}
+
+// Jekyll writes YAML front matter, kramdown block attributes for callouts
+// and details elements with Markdown enabled for toggles
+var Jekyll = &Preset{
+	Name: "jekyll",
+	FrontMatter: func(meta SiteMeta) string {
+		var result strings.Builder
+		result.WriteString("---\n")
+		result.WriteString("title: " + quoteString(meta.Title) + "\n")
+		result.WriteString("date: " + meta.Date.Format(time.RFC3339) + "\n")
+		result.WriteString("last_modified_at: " + meta.LastMod.Format(time.RFC3339) + "\n")
+		fmt.Fprintf(&result, "published: %t\n", !meta.Draft)
+		if meta.Slug != "" {
+			result.WriteString("slug: " + quoteString(meta.Slug) + "\n")
+		}
+		if len(meta.Tags) > 0 {
+			result.WriteString("tags: " + quoteList(meta.Tags) + "\n")
+		}
+		if meta.Weight != nil {
+			fmt.Fprintf(&result, "nav_order: %d\n", *meta.Weight)
+		}
+		result.WriteString("---\n\n")
+		return result.String()
+	},
+	Register: func(r *Renderer) {
+		r.Register(notionapi.BlockTypeCallout, func(r *Renderer, node *Node, depth int) string {
+			text := joinLines(r.RichText(node.Text), "\n> ")
+			return "> " + text + "\n{: .note }\n\n" + r.Children(node, depth+1)
+		})
+		r.Register(notionapi.BlockTypeToggle, renderDetailsToggle(" markdown=\"1\""))
+	},
+}
+
+// Docusaurus writes YAML front matter, admonitions for callouts and
+// details elements for toggles
+var Docusaurus = &Preset{
+	Name: "docusaurus",
+	FrontMatter: func(meta SiteMeta) string {
+		var result strings.Builder
+		result.WriteString("---\n")
+		result.WriteString("title: " + quoteString(meta.Title) + "\n")
+		result.WriteString("date: " + meta.Date.Format(time.RFC3339) + "\n")
+		result.WriteString("last_update:\n")
+		result.WriteString("  date: " + meta.LastMod.Format(time.RFC3339) + "\n")
+		fmt.Fprintf(&result, "draft: %t\n", meta.Draft)
+		if meta.Slug != "" {
+			result.WriteString("slug: " + quoteString(meta.Slug) + "\n")
+		}
+		if len(meta.Tags) > 0 {
+			result.WriteString("tags: " + quoteList(meta.Tags) + "\n")
+		}
+		if meta.Weight != nil {
+			fmt.Fprintf(&result, "sidebar_position: %d\n", *meta.Weight)
+		}
+		result.WriteString("---\n\n")
+		return result.String()
+	},
+	Register: func(r *Renderer) {
+		r.Register(notionapi.BlockTypeCallout, func(r *Renderer, node *Node, depth int) string {
+			text := r.RichText(node.Text)
+			return ":::note\n\n" + text + "\n\n" + blankLineAfter(r.Children(node, 0)) + ":::\n\n"
+		})
+		r.Register(notionapi.BlockTypeToggle, renderDetailsToggle(""))
+	},
+}
+
+// Zola writes TOML front matter with tags as a taxonomy and details elements
+// for toggles. Zola has no built-in callout syntax, so callouts stay quotes.
+var Zola = &Preset{
+	Name: "zola",
+	FrontMatter: func(meta SiteMeta) string {
+		var result strings.Builder
+		result.WriteString("+++\n")
+		result.WriteString("title = " + quoteString(meta.Title) + "\n")
+		result.WriteString("date = " + meta.Date.Format(time.RFC3339) + "\n")
+		result.WriteString("updated = " + meta.LastMod.Format(time.RFC3339) + "\n")
+		fmt.Fprintf(&result, "draft = %t\n", meta.Draft)
+		if meta.Slug != "" {
+			result.WriteString("slug = " + quoteString(meta.Slug) + "\n")
+		}
+		if meta.Weight != nil {
+			fmt.Fprintf(&result, "weight = %d\n", *meta.Weight)
+		}
+		if len(meta.Tags) > 0 {
+			// Tables come last in TOML
+			result.WriteString("\n[taxonomies]\n")
+			result.WriteString("tags = " + quoteList(meta.Tags) + "\n")
+		}
+		result.WriteString("+++\n\n")
+		return result.String()
+	},
+	Register: func(r *Renderer) {
+		r.Register(notionapi.BlockTypeToggle, renderDetailsToggle(""))
+	},
+}
+
+// renderAlertCallout writes a callout as a GitHub-style alert
+func renderAlertCallout(r *Renderer, node *Node, depth int) string {
+	text := joinLines(r.RichText(node.Text), "\n> ")
+	return "> [!NOTE]\n> " + text + "\n\n" + r.Children(node, depth+1)
+}
+
+// renderDetailsToggle returns a renderer writing toggles as details elements
+// with the given attributes
+func renderDetailsToggle(attributes string) BlockRenderer {
+	return func(r *Renderer, node *Node, depth int) string {
+		summary := "<summary>" + FormatRichTextHTML(node.Text) + "</summary>"
+		return "<details" + attributes + ">\n" + summary + "\n\n" + blankLineAfter(r.Children(node, 0)) + "</details>\n\n"
+	}
+}
+
+// blankLineAfter makes non-empty Markdown end with a blank line so that a
+// closing tag or fence that follows is not part of the last block
+func blankLineAfter(markdown string) string {
+	if markdown == "" || strings.HasSuffix(markdown, "\n\n") {
+		return markdown
+	}
+	return markdown + "\n"
+}
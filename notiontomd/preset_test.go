@@ -0,0 +1,201 @@
+package notiontomd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// samplePageInfo returns page metadata with properties for the preset front matter
+func samplePageInfo() PageInfo {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	weight := 2.0
+	return PageInfo{
+		ID:             "page-1",
+		Title:          `Say "hello"`,
+		URL:            "https://www.notion.so/page-1",
+		CreatedTime:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		LastEditedTime: time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC),
+		Properties: map[string]Property{
+			"Published": {Type: "date", Date: &date},
+			"status":    {Type: "status", Text: "Draft"},
+			"Slug":      {Type: "rich_text", Text: "say-hello"},
+			"Tags":      {Type: "multi_select", Values: []string{"go", "notion"}},
+			"Weight":    {Type: "number", Number: &weight},
+		},
+	}
+}
+
+// Helper function to create a toggle block with children
+func createToggleBlock(text string) notionapi.Block {
+	return &notionapi.ToggleBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object:      notionapi.ObjectTypeBlock,
+			ID:          "toggle-1",
+			Type:        notionapi.BlockTypeToggle,
+			HasChildren: true,
+		},
+		Toggle: notionapi.Toggle{
+			RichText: []notionapi.RichText{{Type: "text", PlainText: text}},
+		},
+	}
+}
+
+func TestNewSiteMeta(t *testing.T) {
+	meta := NewSiteMeta(samplePageInfo(), map[string]string{FieldDate: "Published", FieldDraft: "Status"})
+
+	if !meta.Date.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the date property, got %v", meta.Date)
+	}
+	if !meta.Draft {
+		t.Error("Expected a Draft status to mark the page as a draft")
+	}
+	if meta.Slug != "say-hello" {
+		t.Errorf("Expected %q, got %q", "say-hello", meta.Slug)
+	}
+	if !reflect.DeepEqual(meta.Tags, []string{"go", "notion"}) {
+		t.Errorf("Expected the multi-select options as tags, got %v", meta.Tags)
+	}
+	if meta.Weight == nil || *meta.Weight != 2 {
+		t.Errorf("Expected weight 2, got %v", meta.Weight)
+	}
+
+	// Without a date property the creation time is used
+	meta = NewSiteMeta(PageInfo{CreatedTime: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}, nil)
+	if !meta.Date.Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) || meta.Draft || meta.Weight != nil {
+		t.Errorf("Expected defaults from the page metadata, got %+v", meta)
+	}
+}
+
+func TestPropertyValuesSplitsText(t *testing.T) {
+	result := propertyValues(Property{Type: "rich_text", Text: "go, notion,,markdown "})
+	expected := []string{"go", "notion", "markdown"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestPresetFrontMatter(t *testing.T) {
+	meta := NewSiteMeta(samplePageInfo(), map[string]string{FieldDate: "Published", FieldDraft: "Status"})
+
+	tests := []struct {
+		preset   *Preset
+		expected string
+	}{
+		{
+			preset: Hugo,
+			expected: "+++\n" +
+				"title = \"Say \\\"hello\\\"\"\n" +
+				"date = 2024-03-01T00:00:00Z\n" +
+				"lastmod = 2024-01-02T15:30:00Z\n" +
+				"draft = true\n" +
+				"slug = \"say-hello\"\n" +
+				"tags = [\"go\", \"notion\"]\n" +
+				"weight = 2\n" +
+				"+++\n\n",
+		},
+		{
+			preset: Jekyll,
+			expected: "---\n" +
+				"title: \"Say \\\"hello\\\"\"\n" +
+				"date: 2024-03-01T00:00:00Z\n" +
+				"last_modified_at: 2024-01-02T15:30:00Z\n" +
+				"published: false\n" +
+				"slug: \"say-hello\"\n" +
+				"tags: [\"go\", \"notion\"]\n" +
+				"nav_order: 2\n" +
+				"---\n\n",
+		},
+		{
+			preset: Docusaurus,
+			expected: "---\n" +
+				"title: \"Say \\\"hello\\\"\"\n" +
+				"date: 2024-03-01T00:00:00Z\n" +
+				"last_update:\n" +
+				"  date: 2024-01-02T15:30:00Z\n" +
+				"draft: true\n" +
+				"slug: \"say-hello\"\n" +
+				"tags: [\"go\", \"notion\"]\n" +
+				"sidebar_position: 2\n" +
+				"---\n\n",
+		},
+		{
+			preset: Zola,
+			expected: "+++\n" +
+				"title = \"Say \\\"hello\\\"\"\n" +
+				"date = 2024-03-01T00:00:00Z\n" +
+				"updated = 2024-01-02T15:30:00Z\n" +
+				"draft = true\n" +
+				"slug = \"say-hello\"\n" +
+				"weight = 2\n" +
+				"\n[taxonomies]\n" +
+				"tags = [\"go\", \"notion\"]\n" +
+				"+++\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset.Name, func(t *testing.T) {
+			result := tt.preset.FrontMatter(meta)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPresetBlocks(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createCalloutBlock("Heads up"), Indent: 0},
+		{Block: createToggleBlock("More"), Indent: 0},
+		{Block: createBulletedListBlock("block-1", "Hidden", false), Indent: 1},
+	}
+
+	tests := []struct {
+		preset   *Preset
+		expected string
+	}{
+		{
+			preset: Hugo,
+			expected: "> [!NOTE]\n> Heads up\n\n" +
+				"{{< details summary=\"More\" >}}\n\n- Hidden\n\n{{< /details >}}\n\n",
+		},
+		{
+			preset: Jekyll,
+			expected: "> Heads up\n{: .note }\n\n" +
+				"<details markdown=\"1\">\n<summary>More</summary>\n\n- Hidden\n\n</details>\n\n",
+		},
+		{
+			preset: Docusaurus,
+			expected: ":::note\n\nHeads up\n\n:::\n\n" +
+				"<details>\n<summary>More</summary>\n\n- Hidden\n\n</details>\n\n",
+		},
+		{
+			preset: Zola,
+			expected: "> Heads up\n\n" +
+				"<details>\n<summary>More</summary>\n\n- Hidden\n\n</details>\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset.Name, func(t *testing.T) {
+			doc := (&Converter{opts: Options{Preset: tt.preset, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+			if doc.Content != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, doc.Content)
+			}
+		})
+	}
+}
+
+func TestLookupPreset(t *testing.T) {
+	for _, name := range []string{"hugo", "jekyll", "docusaurus", "zola", "zenn", "qiita", "obsidian", "hatena", "hatena-atompub", "marp", "revealjs"} {
+		if preset := LookupPreset(name); preset == nil || preset.Name != name {
+			t.Errorf("Expected preset %q, got %v", name, preset)
+		}
+	}
+	if LookupPreset("gatsby") != nil {
+		t.Error("Expected no preset for an unknown name")
+	}
+}
@@ -0,0 +1,117 @@
+package notiontomd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// Property is a normalized page property. Only the fields that apply to
+// the property type are set.
+type Property struct {
+	// Type is the Notion property type, e.g. "select" or "multi_select"
+	Type string `json:"type"`
+	// Text is the value of title, rich_text, select, status, url, email,
+	// phone_number and string formula properties
+	Text string `json:"text,omitempty"`
+	// Values are the options of multi_select properties and the names of people
+	Values []string `json:"values,omitempty"`
+	// Number is the value of number and numeric formula properties
+	Number *float64 `json:"number,omitempty"`
+	// Checkbox is the value of checkbox and boolean formula properties
+	Checkbox bool `json:"checkbox,omitempty"`
+	// Date is the start of date properties and the time of created_time and
+	// last_edited_time properties
+	Date *time.Time `json:"date,omitempty"`
+}
+
+// NewProperties normalizes the properties of a page. Empty properties are omitted.
+func NewProperties(properties notionapi.Properties) map[string]Property {
+	result := make(map[string]Property)
+
+	for name, prop := range properties {
+		p := Property{Type: string(prop.GetType())}
+
+		switch v := prop.(type) {
+		case *notionapi.TitleProperty:
+			p.Text = PlainText(NewSpans(v.Title))
+		case *notionapi.RichTextProperty:
+			p.Text = PlainText(NewSpans(v.RichText))
+		case *notionapi.SelectProperty:
+			p.Text = v.Select.Name
+		case *notionapi.StatusProperty:
+			p.Text = v.Status.Name
+		case *notionapi.MultiSelectProperty:
+			for _, option := range v.MultiSelect {
+				p.Values = append(p.Values, option.Name)
+			}
+		case *notionapi.PeopleProperty:
+			for _, user := range v.People {
+				p.Values = append(p.Values, user.Name)
+			}
+		case *notionapi.NumberProperty:
+			number := v.Number
+			p.Number = &number
+		case *notionapi.CheckboxProperty:
+			p.Checkbox = v.Checkbox
+		case *notionapi.DateProperty:
+			p.Date = dateStart(v.Date)
+		case *notionapi.CreatedTimeProperty:
+			created := v.CreatedTime
+			p.Date = &created
+		case *notionapi.LastEditedTimeProperty:
+			edited := v.LastEditedTime
+			p.Date = &edited
+		case *notionapi.URLProperty:
+			p.Text = v.URL
+		case *notionapi.EmailProperty:
+			p.Text = v.Email
+		case *notionapi.PhoneNumberProperty:
+			p.Text = v.PhoneNumber
+		case *notionapi.FormulaProperty:
+			switch v.Formula.Type {
+			case "string":
+				p.Text = v.Formula.String
+			case "number":
+				number := v.Formula.Number
+				p.Number = &number
+			case "boolean":
+				p.Checkbox = v.Formula.Boolean
+			case "date":
+				p.Date = dateStart(v.Formula.Date)
+			}
+		default:
+			continue
+		}
+
+		if p.Text == "" && p.Values == nil && p.Number == nil && !p.Checkbox && p.Date == nil && p.Type != "checkbox" {
+			continue
+		}
+		result[name] = p
+	}
+
+	return result
+}
+
+// dateStart returns the start of a date value, or nil when it is empty
+func dateStart(date *notionapi.DateObject) *time.Time {
+	if date == nil || date.Start == nil {
+		return nil
+	}
+	start := time.Time(*date.Start)
+	return &start
+}
+
+// LookupProperty returns the property with the given name, ignoring case
+func (info PageInfo) LookupProperty(name string) (Property, bool) {
+	if p, ok := info.Properties[name]; ok {
+		return p, true
+	}
+	for key, p := range info.Properties {
+		if strings.EqualFold(key, name) {
+			return p, true
+		}
+	}
+	return Property{}, false
+}
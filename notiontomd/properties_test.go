@@ -0,0 +1,51 @@
+package notiontomd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestNewProperties(t *testing.T) {
+	start := notionapi.Date(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	properties := notionapi.Properties{
+		"Name":   &notionapi.TitleProperty{Type: "title", Title: []notionapi.RichText{{Type: "text", PlainText: "Page"}}},
+		"Status": &notionapi.SelectProperty{Type: "select", Select: notionapi.Option{Name: "Draft"}},
+		"Tags": &notionapi.MultiSelectProperty{Type: "multi_select", MultiSelect: []notionapi.Option{
+			{Name: "go"}, {Name: "notion"},
+		}},
+		"Weight":    &notionapi.NumberProperty{Type: "number", Number: 3},
+		"Published": &notionapi.CheckboxProperty{Type: "checkbox", Checkbox: false},
+		"Date":      &notionapi.DateProperty{Type: "date", Date: &notionapi.DateObject{Start: &start}},
+		"Empty":     &notionapi.RichTextProperty{Type: "rich_text"},
+	}
+
+	result := NewProperties(properties)
+
+	weight := 3.0
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	expected := map[string]Property{
+		"Name":      {Type: "title", Text: "Page"},
+		"Status":    {Type: "select", Text: "Draft"},
+		"Tags":      {Type: "multi_select", Values: []string{"go", "notion"}},
+		"Weight":    {Type: "number", Number: &weight},
+		"Published": {Type: "checkbox"},
+		"Date":      {Type: "date", Date: &date},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestLookupPropertyIgnoresCase(t *testing.T) {
+	info := PageInfo{Properties: map[string]Property{"Tags": {Type: "multi_select", Values: []string{"go"}}}}
+
+	if _, ok := info.LookupProperty("tags"); !ok {
+		t.Error("Expected to find the property ignoring case")
+	}
+	if _, ok := info.LookupProperty("Missing"); ok {
+		t.Error("Expected no property for an unknown name")
+	}
+}
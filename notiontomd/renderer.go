@@ -0,0 +1,98 @@
+package notiontomd
+
+import (
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// BlockRenderer renders a block at the given nesting depth to Markdown. The
+// returned text is appended to the output as is, so it includes the trailing
+// newlines. It also includes the nested blocks, which most renderers produce
+// by calling Children with depth+1.
+type BlockRenderer func(r *Renderer, node *Node, depth int) string
+
+// RichTextRenderer renders the rich text of a block to Markdown
+type RichTextRenderer func(spans []Span) string
+
+// Renderer converts blocks to Markdown with a renderer per block type and
+// must be created with NewRenderer. Blocks of types without a renderer are
+// skipped, but their nested blocks are rendered. A Renderer must not be
+// modified while it is converting.
+type Renderer struct {
+	blocks   map[notionapi.BlockType]BlockRenderer
+	richText RichTextRenderer
+	// truncated is written after the indentation in place of omitted nested blocks
+	truncated string
+	// separateLists writes a blank line between a top-level list and the next block
+	separateLists bool
+}
+
+// NewRenderer creates a Renderer with the built-in renderers registered
+func NewRenderer() *Renderer {
+	return &Renderer{
+		blocks:    defaultBlockRenderers(),
+		richText:  FormatRichText,
+		truncated: "- " + truncatedMarker,
+	}
+}
+
+// Register sets the renderer of a block type, replacing any previous one
+func (r *Renderer) Register(blockType notionapi.BlockType, render BlockRenderer) {
+	r.blocks[blockType] = render
+}
+
+// Lookup returns the renderer of a block type, or nil. Custom renderers can
+// use it to fall back to the renderer they replace.
+func (r *Renderer) Lookup(blockType notionapi.BlockType) BlockRenderer {
+	return r.blocks[blockType]
+}
+
+// SetRichText replaces the rich-text renderer used by all block renderers
+func (r *Renderer) SetRichText(render RichTextRenderer) {
+	r.richText = render
+}
+
+// RichText renders rich text with the configured rich-text renderer
+func (r *Renderer) RichText(spans []Span) string {
+	return r.richText(spans)
+}
+
+// Render converts a block tree to Markdown
+func (r *Renderer) Render(nodes []*Node) string {
+	var result strings.Builder
+	for i, node := range nodes {
+		if r.separateLists && i > 0 && isListOrToggle(nodes[i-1]) && !isListOrToggle(node) {
+			result.WriteString("\n")
+		}
+		// Blocks fetched below the top level keep their indent
+		result.WriteString(r.Node(node, node.indent))
+	}
+	return result.String()
+}
+
+// Node renders a single block and its nested blocks at the given depth
+func (r *Renderer) Node(node *Node, depth int) string {
+	if render, ok := r.blocks[node.Type]; ok {
+		return render(r, node, depth)
+	}
+	return r.Children(node, depth+1)
+}
+
+// Children renders the nested blocks of a node at the given depth, followed
+// by a visible marker when they were omitted by the depth limit
+func (r *Renderer) Children(node *Node, depth int) string {
+	var result strings.Builder
+	for _, child := range node.Children {
+		result.WriteString(r.Node(child, depth))
+	}
+	if node.Truncated {
+		result.WriteString(Indent(depth) + r.truncated + "\n")
+	}
+	return result.String()
+}
+
+// Indent returns the indentation of a nested list item in Markdown, 2 spaces per level
+func Indent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
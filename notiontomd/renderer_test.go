@@ -0,0 +1,158 @@
+package notiontomd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// Helper function to create a heading 1 block
+func createHeading1Block(text string) notionapi.Block {
+	return &notionapi.Heading1Block{
+		BasicBlock: notionapi.BasicBlock{
+			Object: "block",
+			Type:   notionapi.BlockTypeHeading1,
+		},
+		Heading1: notionapi.Heading{
+			RichText: []notionapi.RichText{
+				{PlainText: text},
+			},
+		},
+	}
+}
+
+// Helper function to create a callout block
+func createCalloutBlock(text string) notionapi.Block {
+	return &notionapi.CalloutBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: "block",
+			Type:   notionapi.BlockTypeCallout,
+		},
+		Callout: notionapi.Callout{
+			RichText: []notionapi.RichText{
+				{PlainText: text},
+			},
+		},
+	}
+}
+
+func TestRendererDefaultsMatchConvert(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createParagraphBlock("block-1", false), Indent: 0},
+		{Block: createBulletedListBlock("block-2", "Item", false), Indent: 0},
+		{Block: createCalloutBlock("Note"), Indent: 0},
+	}
+
+	result := NewRenderer().Render(NewNodes(blocks))
+	expected := Convert(blocks)
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestRendererOverrideBlock(t *testing.T) {
+	r := NewRenderer()
+	r.Register(notionapi.BlockTypeCallout, func(r *Renderer, node *Node, depth int) string {
+		return "> [!NOTE]\n> " + r.RichText(node.Text) + "\n\n"
+	})
+
+	blocks := []BlockWithIndent{
+		{Block: createCalloutBlock("Be careful"), Indent: 0},
+	}
+
+	result := r.Render(NewNodes(blocks))
+	expected := "> [!NOTE]\n> Be careful\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestRendererRegisterUnsupportedBlock(t *testing.T) {
+	r := NewRenderer()
+	r.Register(notionapi.BlockTypeToDo, func(r *Renderer, node *Node, depth int) string {
+		mark := " "
+		if node.Checked {
+			mark = "x"
+		}
+		return Indent(depth) + "- [" + mark + "] " + r.RichText(node.Text) + "\n" + r.Children(node, depth+1)
+	})
+
+	blocks := []BlockWithIndent{
+		{
+			Block: &notionapi.ToDoBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object: "block",
+					Type:   notionapi.BlockTypeToDo,
+				},
+				ToDo: notionapi.ToDo{
+					RichText: []notionapi.RichText{
+						{
+							PlainText: "Done",
+						},
+					},
+					Checked: true,
+				},
+			},
+			Indent: 1,
+		},
+	}
+
+	if result := Convert(blocks); result != "" {
+		t.Errorf("Expected to_do to be skipped by default, got %q", result)
+	}
+
+	result := r.Render(NewNodes(blocks))
+	expected := "  - [x] Done\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestRendererLookupFallback(t *testing.T) {
+	r := NewRenderer()
+	paragraph := r.Lookup(notionapi.BlockTypeParagraph)
+	if paragraph == nil {
+		t.Fatal("Expected a built-in paragraph renderer")
+	}
+	r.Register(notionapi.BlockTypeParagraph, func(r *Renderer, node *Node, depth int) string {
+		return "<!-- " + node.ID + " -->\n" + paragraph(r, node, depth)
+	})
+
+	blocks := []BlockWithIndent{
+		{Block: createParagraphBlock("block-1", false), Indent: 0},
+	}
+
+	result := r.Render(NewNodes(blocks))
+	expected := "<!-- block-1 -->\nTest block\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	if r.Lookup(notionapi.BlockTypeImage) != nil {
+		t.Error("Expected no renderer for image blocks")
+	}
+}
+
+func TestRendererSetRichText(t *testing.T) {
+	r := NewRenderer()
+	r.SetRichText(func(spans []Span) string {
+		return strings.ToUpper(FormatRichText(spans))
+	})
+
+	blocks := []BlockWithIndent{
+		{Block: createHeading1Block("Title"), Indent: 0},
+		{Block: createParagraphBlock("block-1", false), Indent: 0},
+	}
+
+	result := r.Render(NewNodes(blocks))
+	expected := "# TITLE\n\nTEST BLOCK\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,293 @@
+package notiontomd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// RoundTripDifference is a semantic difference between a block and what it
+// became after being converted to Markdown and parsed back
+type RoundTripDifference struct {
+	// Path is the position of the block in the original tree, e.g. "2.0" for
+	// the first child of the third top-level block
+	Path string
+	// Type is the type of the original block, or of the parsed block when
+	// the difference is an added block
+	Type notionapi.BlockType
+	// Message describes the difference
+	Message string
+}
+
+// String formats the difference as "path type: message"
+func (d RoundTripDifference) String() string {
+	return d.Path + " " + string(d.Type) + ": " + d.Message
+}
+
+// DecodeBlocks reads blocks in the JSON of the Notion API: an array of
+// blocks, or a response object with the blocks in "results". Nested
+// blocks are read from the "children" of each block, as in append requests.
+func DecodeBlocks(data []byte) ([]BlockWithIndent, error) {
+	var response struct {
+		Results notionapi.Blocks `json:"results"`
+	}
+	var blocks notionapi.Blocks
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, fmt.Errorf("failed to decode blocks: %w", err)
+		}
+		blocks = response.Results
+	} else if err := json.Unmarshal(data, &blocks); err != nil {
+		return nil, fmt.Errorf("failed to decode blocks: %w", err)
+	}
+	return flattenBlocks(blocks, 0), nil
+}
+
+// flattenBlocks lists blocks and their nested blocks with their indent, the
+// way FetchAllBlocks returns them
+func flattenBlocks(blocks []notionapi.Block, indent int) []BlockWithIndent {
+	var result []BlockWithIndent
+	for _, block := range blocks {
+		result = append(result, BlockWithIndent{Block: block, Indent: indent})
+		result = append(result, flattenBlocks(nestedBlocks(block), indent+1)...)
+	}
+	return result
+}
+
+// nestedBlocks returns the children included in a block object
+func nestedBlocks(block notionapi.Block) notionapi.Blocks {
+	switch b := block.(type) {
+	case *notionapi.Heading1Block:
+		return b.Heading1.Children
+	case *notionapi.Heading2Block:
+		return b.Heading2.Children
+	case *notionapi.Heading3Block:
+		return b.Heading3.Children
+	case *notionapi.ToggleBlock:
+		return b.Toggle.Children
+	case *notionapi.CalloutBlock:
+		return b.Callout.Children
+	case *notionapi.TableBlock:
+		return b.Table.Children
+	}
+	_, children := splitChildren(block)
+	return children
+}
+
+// RoundTrip converts nodes to Markdown with r, or the default renderer when
+// r is nil, parses the Markdown with ParseMarkdown and returns the
+// Markdown with the differences between the original and parsed blocks
+func RoundTrip(nodes []*Node, r *Renderer) (string, []RoundTripDifference) {
+	if r == nil {
+		r = NewRenderer()
+	}
+	markdown := r.Render(nodes)
+	parsed := NewNodes(flattenBlocks(ParseMarkdown(markdown), 0))
+
+	var diffs []RoundTripDifference
+	compareNodeLists(nodes, parsed, "", &diffs)
+	return markdown, diffs
+}
+
+// compareNodeLists aligns sibling blocks and compares the aligned pairs.
+// Blocks between aligned pairs are compared by position, and the rest are
+// reported as missing or added.
+func compareNodeLists(want, got []*Node, prefix string, diffs *[]RoundTripDifference) {
+	path := func(i int) string {
+		return prefix + strconv.Itoa(i)
+	}
+
+	matches := alignNodes(want, got)
+	i, j := 0, 0
+	for _, match := range append(matches, [2]int{len(want), len(got)}) {
+		for ; i < match[0] && j < match[1]; i, j = i+1, j+1 {
+			compareNodes(want[i], got[j], path(i), diffs)
+		}
+		for ; i < match[0]; i++ {
+			*diffs = append(*diffs, RoundTripDifference{Path: path(i), Type: want[i].Type, Message: "missing after the round trip"})
+		}
+		for ; j < match[1]; j++ {
+			*diffs = append(*diffs, RoundTripDifference{Path: path(i), Type: got[j].Type, Message: "added by the round trip"})
+		}
+		if i < len(want) && j < len(got) {
+			compareNodes(want[i], got[j], path(i), diffs)
+			i, j = i+1, j+1
+		}
+	}
+}
+
+// alignNodes pairs sibling blocks so that as many blocks as possible keep
+// their type and text. A pair scores 2 when both are the same and 1 when
+// one of them is, and the pairs with the highest total score are returned.
+func alignNodes(want, got []*Node) [][2]int {
+	score := func(a, b *Node) int {
+		n := 0
+		if a.Type == b.Type {
+			n++
+		}
+		if text := PlainText(a.Text); text != "" && text == PlainText(b.Text) {
+			n++
+		}
+		return n
+	}
+
+	// best[i][j] is the highest score of pairs from want[i:] and got[j:]
+	best := make([][]int, len(want)+1)
+	for i := range best {
+		best[i] = make([]int, len(got)+1)
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			best[i][j] = max(best[i+1][j], best[i][j+1])
+			if n := score(want[i], got[j]); n > 0 {
+				best[i][j] = max(best[i][j], n+best[i+1][j+1])
+			}
+		}
+	}
+
+	var matches [][2]int
+	for i, j := 0, 0; i < len(want) && j < len(got); {
+		switch n := score(want[i], got[j]); {
+		case n > 0 && best[i][j] == n+best[i+1][j+1]:
+			matches = append(matches, [2]int{i, j})
+			i++
+			j++
+		case best[i+1][j] >= best[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
+
+// compareNodes reports the differences between a block and its round-tripped block
+func compareNodes(want, got *Node, path string, diffs *[]RoundTripDifference) {
+	report := func(format string, args ...any) {
+		*diffs = append(*diffs, RoundTripDifference{Path: path, Type: want.Type, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if want.Type != got.Type {
+		report("became %s", got.Type)
+	}
+	if message := compareSpans(want.Text, got.Text); message != "" {
+		report("text %s", message)
+	}
+	if message := compareSpans(want.Caption, got.Caption); message != "" {
+		report("caption %s", message)
+	}
+	if want.Checked != got.Checked {
+		report("checked %t became %t", want.Checked, got.Checked)
+	}
+	if want.Language != got.Language {
+		report("language %q became %q", want.Language, got.Language)
+	}
+	if want.Toggleable != got.Toggleable {
+		report("toggleable %t became %t", want.Toggleable, got.Toggleable)
+	}
+	if want.Icon != got.Icon {
+		report("icon %q became %q", want.Icon, got.Icon)
+	}
+	if want.URL != got.URL {
+		report("url %q became %q", want.URL, got.URL)
+	}
+	if want.Expression != got.Expression {
+		report("expression %q became %q", want.Expression, got.Expression)
+	}
+	if want.ColumnHeader != got.ColumnHeader || want.RowHeader != got.RowHeader {
+		report("headers (column %t, row %t) became (column %t, row %t)", want.ColumnHeader, want.RowHeader, got.ColumnHeader, got.RowHeader)
+	}
+	if wantCells, gotCells := formatCells(want.Cells), formatCells(got.Cells); wantCells != gotCells {
+		report("cells %q became %q", wantCells, gotCells)
+	}
+
+	compareNodeLists(want.Children, got.Children, path+".", diffs)
+}
+
+// compareSpans describes how rich text changed, or returns "" when the text
+// and its formatting are the same. Spans are compared after merging
+// neighbours with the same formatting, so only the meaning is compared.
+func compareSpans(want, got []Span) string {
+	want, got = mergeSpans(want), mergeSpans(got)
+	if PlainText(want) != PlainText(got) {
+		return fmt.Sprintf("%q became %q", PlainText(want), PlainText(got))
+	}
+	if len(want) == len(got) {
+		same := true
+		for i := range want {
+			if !sameFormatting(want[i], got[i]) {
+				same = false
+				break
+			}
+		}
+		if same {
+			return ""
+		}
+	}
+	return fmt.Sprintf("formatting %s became %s", describeSpans(want), describeSpans(got))
+}
+
+// mergeSpans joins neighbouring spans with the same formatting
+func mergeSpans(spans []Span) []Span {
+	var result []Span
+	for _, span := range spans {
+		if span.Text == "" {
+			continue
+		}
+		if n := len(result); n > 0 && sameFormatting(result[n-1], span) {
+			result[n-1].Text += span.Text
+			continue
+		}
+		span.Type = "text"
+		result = append(result, span)
+	}
+	return result
+}
+
+// sameFormatting reports whether two spans have the same annotations and link.
+// Mentions are compared as plain text, since Markdown has no syntax for them.
+func sameFormatting(a, b Span) bool {
+	return a.Bold == b.Bold && a.Italic == b.Italic && a.Strikethrough == b.Strikethrough &&
+		a.Underline == b.Underline && a.Code == b.Code && a.Color == b.Color && a.Href == b.Href
+}
+
+// describeSpans formats spans with their formatting for a difference report
+func describeSpans(spans []Span) string {
+	parts := make([]string, len(spans))
+	for i, span := range spans {
+		var marks []string
+		for _, mark := range []struct {
+			set  bool
+			name string
+		}{
+			{span.Bold, "bold"},
+			{span.Italic, "italic"},
+			{span.Strikethrough, "strikethrough"},
+			{span.Underline, "underline"},
+			{span.Code, "code"},
+			{span.Color != "", span.Color},
+			{span.Href != "", "link " + span.Href},
+		} {
+			if mark.set {
+				marks = append(marks, mark.name)
+			}
+		}
+		parts[i] = strconv.Quote(span.Text)
+		if len(marks) > 0 {
+			parts[i] += "(" + strings.Join(marks, ", ") + ")"
+		}
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+// formatCells formats table cells as plain text rows for comparison
+func formatCells(cells [][]Span) string {
+	texts := make([]string, len(cells))
+	for i, cell := range cells {
+		texts[i] = PlainText(cell)
+	}
+	return strings.Join(texts, " | ")
+}
@@ -0,0 +1,104 @@
+package notiontomd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Helper function to load a block fixture from testdata/roundtrip
+func loadRoundTripFixture(t *testing.T, name string) []BlockWithIndent {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "roundtrip", name+".json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	blocks, err := DecodeBlocks(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return blocks
+}
+
+func TestRoundTrip(t *testing.T) {
+	// The differences document what the Markdown renderer and ParseMarkdown
+	// lose. Update them when either learns to keep more.
+	expected := map[string][]string{
+		"supported": nil,
+		"lossy": {
+			"0 to_do: missing after the round trip",
+			"1 toggle: became bulleted_list_item",
+			"1.0 paragraph: missing after the round trip",
+			"2 paragraph: added by the round trip",
+			`2 paragraph: text formatting ["Underlined"(underline, red)] became ["Underlined"]`,
+			"3 table: missing after the round trip",
+		},
+	}
+
+	paths, err := filepath.Glob(filepath.Join("testdata", "roundtrip", "*.json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, path := range paths {
+		fixture := strings.TrimSuffix(filepath.Base(path), ".json")
+		want, ok := expected[fixture]
+		if !ok {
+			t.Errorf("%s: no expected differences for the fixture", fixture)
+			continue
+		}
+
+		markdown, diffs := RoundTrip(NewNodes(loadRoundTripFixture(t, fixture)), nil)
+
+		var result []string
+		for _, diff := range diffs {
+			result = append(result, diff.String())
+		}
+		if !reflect.DeepEqual(result, want) {
+			t.Errorf("%s: expected differences\n%s\ngot\n%s\nMarkdown:\n%s",
+				fixture, strings.Join(want, "\n"), strings.Join(result, "\n"), markdown)
+		}
+	}
+	if len(paths) != len(expected) {
+		t.Errorf("Expected %d fixtures, got %v", len(expected), paths)
+	}
+}
+
+func TestDecodeBlocks(t *testing.T) {
+	blocks := loadRoundTripFixture(t, "supported")
+
+	if len(blocks) != 13 {
+		t.Fatalf("Expected 13 blocks, got %d", len(blocks))
+	}
+	// Nested blocks follow their parent with a larger indent
+	if blocks[4].Block.GetID() != "b5" || blocks[4].Indent != 1 {
+		t.Errorf("Expected the nested list item at indent 1, got %s at %d", blocks[4].Block.GetID(), blocks[4].Indent)
+	}
+
+	if _, err := DecodeBlocks([]byte(`{"results": [`)); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+func TestRoundTripReportsChanges(t *testing.T) {
+	want := []*Node{
+		{Type: "code", Text: []Span{{Type: "text", Text: "x := 1"}}, Language: "go"},
+		{Type: "paragraph", Text: []Span{{Type: "text", Text: "a"}, {Type: "text", Text: "b"}}},
+	}
+	got := []*Node{
+		{Type: "code", Text: []Span{{Type: "text", Text: "x := 1"}}, Language: "plain text"},
+		{Type: "paragraph", Text: []Span{{Type: "text", Text: "ab", Bold: true}}},
+	}
+
+	var diffs []RoundTripDifference
+	compareNodeLists(want, got, "", &diffs)
+
+	expected := []RoundTripDifference{
+		{Path: "0", Type: "code", Message: `language "go" became "plain text"`},
+		{Path: "1", Type: "paragraph", Message: `text formatting ["ab"] became ["ab"(bold)]`},
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, diffs)
+	}
+}
@@ -0,0 +1,161 @@
+package notiontomd
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// SlideBreak selects which blocks start a new slide
+type SlideBreak string
+
+const (
+	// SlideBreakAuto uses dividers when the page has a top-level divider
+	// and headings otherwise
+	SlideBreakAuto SlideBreak = "auto"
+	// SlideBreakDivider starts a slide at every top-level divider
+	SlideBreakDivider SlideBreak = "divider"
+	// SlideBreakHeading starts a slide at every top-level heading_1 and heading_2
+	SlideBreakHeading SlideBreak = "heading"
+)
+
+// SlideOptions configures NewMarp and NewRevealJS
+type SlideOptions struct {
+	// Break selects the slide boundaries. Empty means SlideBreakAuto.
+	Break SlideBreak
+}
+
+// marpDirectives are the Marp directives that are read from page
+// properties with the same name, ignoring case
+var marpDirectives = []string{"theme", "paginate", "size", "header", "footer", "class", "backgroundColor", "color", "math"}
+
+// Marp is the Marp preset with automatic slide breaks
+var Marp = NewMarp(SlideOptions{})
+
+// RevealJS is the reveal.js preset with automatic slide breaks
+var RevealJS = NewRevealJS(SlideOptions{})
+
+// NewMarp creates a preset for Marp slide decks. The page metadata becomes
+// global directives and toggles become presenter notes.
+func NewMarp(opts SlideOptions) *Preset {
+	return &Preset{
+		Name:        "marp",
+		FrontMatter: marpFrontMatter,
+		Register: func(r *Renderer) {
+			r.Register(notionapi.BlockTypeToggle, func(r *Renderer, node *Node, depth int) string {
+				// Marp shows HTML comments as presenter notes
+				notes := strings.ReplaceAll(speakerNotes(node), "-->", "-- >")
+				return "<!--\n" + notes + "\n-->\n\n"
+			})
+		},
+		Prepare: slideBreaks(opts.Break),
+	}
+}
+
+// NewRevealJS creates a preset for reveal.js decks written in Markdown, as
+// read by reveal-md or the Markdown plugin. Toggles become speaker notes.
+func NewRevealJS(opts SlideOptions) *Preset {
+	return &Preset{
+		Name:        "revealjs",
+		FrontMatter: revealFrontMatter,
+		Register: func(r *Renderer) {
+			r.Register(notionapi.BlockTypeToggle, func(r *Renderer, node *Node, depth int) string {
+				return "<aside class=\"notes\">\n" + html.EscapeString(speakerNotes(node)) + "\n</aside>\n\n"
+			})
+		},
+		Prepare: slideBreaks(opts.Break),
+	}
+}
+
+// marpFrontMatter writes the global directives of a Marp deck
+func marpFrontMatter(meta SiteMeta) string {
+	var result strings.Builder
+
+	result.WriteString("---\n")
+	result.WriteString("marp: true\n")
+	result.WriteString("title: " + quoteString(meta.Title) + "\n")
+	result.WriteString("url: " + meta.Info.URL + "\n")
+	if len(meta.Tags) > 0 {
+		result.WriteString("keywords: " + quoteString(strings.Join(meta.Tags, ",")) + "\n")
+	}
+	writeDirectives(&result, meta.Info, marpDirectives)
+	result.WriteString("---\n\n")
+
+	return result.String()
+}
+
+// revealFrontMatter writes the front matter read by reveal-md
+func revealFrontMatter(meta SiteMeta) string {
+	var result strings.Builder
+
+	result.WriteString("---\n")
+	result.WriteString("title: " + quoteString(meta.Title) + "\n")
+	writeDirectives(&result, meta.Info, []string{"theme", "highlightTheme"})
+	result.WriteString("---\n\n")
+
+	return result.String()
+}
+
+// writeDirectives writes the page properties with the given names as
+// front matter entries
+func writeDirectives(w *strings.Builder, info PageInfo, names []string) {
+	for _, name := range names {
+		p, ok := info.LookupProperty(name)
+		if !ok {
+			continue
+		}
+		switch {
+		case p.Type == "checkbox":
+			fmt.Fprintf(w, "%s: %t\n", name, p.Checkbox)
+		case p.Number != nil:
+			fmt.Fprintf(w, "%s: %v\n", name, *p.Number)
+		case p.Text != "":
+			w.WriteString(name + ": " + quoteString(p.Text) + "\n")
+		}
+	}
+}
+
+// speakerNotes returns the text of a toggle and its nested blocks as plain text
+func speakerNotes(node *Node) string {
+	notes := PlainText(node.Text)
+	if children := strings.TrimRight(NewTextRenderer(false).Render(node.Children), "\n"); children != "" {
+		notes += "\n" + children
+	}
+	return notes
+}
+
+// slideBreaks returns a Prepare function that marks slide boundaries with
+// dividers, which are written as the --- slide separator
+func slideBreaks(mode SlideBreak) func(nodes []*Node) []*Node {
+	return func(nodes []*Node) []*Node {
+		if mode == SlideBreakDivider || (mode != SlideBreakHeading && hasDivider(nodes)) {
+			return nodes
+		}
+
+		// Headings start slides, so dividers would only add empty ones
+		result := make([]*Node, 0, len(nodes))
+		for _, node := range nodes {
+			if node.Type == notionapi.BlockTypeDivider {
+				continue
+			}
+			level := headingLevel(node.Type)
+			if (level == 1 || level == 2) && len(result) > 0 {
+				result = append(result, &Node{Type: notionapi.BlockTypeDivider, indent: node.indent})
+			}
+			result = append(result, node)
+		}
+		return result
+	}
+}
+
+// hasDivider reports whether a top-level block is a divider
+func hasDivider(nodes []*Node) bool {
+	for _, node := range nodes {
+		if node.Type == notionapi.BlockTypeDivider {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package notiontomd
+
+import (
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// Helper function to create a divider block
+func createDividerBlock() notionapi.Block {
+	return &notionapi.DividerBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			Type:   notionapi.BlockTypeDivider,
+		},
+	}
+}
+
+func TestMarpHeadingBreaks(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createHeading1Block("Intro"), Indent: 0},
+		{Block: createTextParagraphBlock("Hello"), Indent: 0},
+		{Block: createToggleBlock("Say hi"), Indent: 0},
+		{Block: createTextParagraphBlock("Wave --> smile"), Indent: 1},
+		{Block: createHeading1Block("Outro"), Indent: 0},
+	}
+
+	doc := (&Converter{opts: Options{Preset: Marp, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+	expected := "# Intro\n\n" +
+		"Hello\n\n" +
+		"<!--\nSay hi\nWave -- > smile\n-->\n\n" +
+		"---\n\n" +
+		"# Outro\n\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+}
+
+func TestSlideBreakDivider(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createHeading1Block("Intro"), Indent: 0},
+		{Block: createHeading1Block("Still intro"), Indent: 0},
+		{Block: createDividerBlock(), Indent: 0},
+		{Block: createTextParagraphBlock("Next"), Indent: 0},
+	}
+
+	for _, mode := range []SlideBreak{SlideBreakAuto, SlideBreakDivider} {
+		preset := NewRevealJS(SlideOptions{Break: mode})
+		doc := (&Converter{opts: Options{Preset: preset, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+		expected := "# Intro\n\n# Still intro\n\n---\n\nNext\n\n"
+		if doc.Content != expected {
+			t.Errorf("%s: expected %q, got %q", mode, expected, doc.Content)
+		}
+	}
+
+	preset := NewRevealJS(SlideOptions{Break: SlideBreakHeading})
+	doc := (&Converter{opts: Options{Preset: preset, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+	expected := "# Intro\n\n---\n\n# Still intro\n\nNext\n\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+}
+
+func TestRevealJSSpeakerNotes(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createToggleBlock("Mention <b>"), Indent: 0},
+	}
+
+	doc := (&Converter{opts: Options{Preset: RevealJS, OmitFrontMatter: true}}).mustRender(t, PageInfo{}, blocks)
+
+	expected := "<aside class=\"notes\">\nMention &lt;b&gt;\n</aside>\n\n"
+	if doc.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, doc.Content)
+	}
+}
+
+func TestMarpFrontMatter(t *testing.T) {
+	info := samplePageInfo()
+	info.Properties["Paginate"] = Property{Type: "checkbox", Checkbox: true}
+	info.Properties["Theme"] = Property{Type: "select", Text: "gaia"}
+
+	result := Marp.FrontMatter(NewSiteMeta(info, nil))
+
+	expected := "---\n" +
+		"marp: true\n" +
+		"title: \"Say \\\"hello\\\"\"\n" +
+		"url: https://www.notion.so/page-1\n" +
+		"keywords: \"go,notion\"\n" +
+		"theme: \"gaia\"\n" +
+		"paginate: true\n" +
+		"---\n\n"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,95 @@
+package notiontomd
+
+import (
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// TextHeader generates the plain-text header from page metadata: the title
+// followed by the page URL
+func TextHeader(info PageInfo) string {
+	return info.Title + "\n" + info.URL + "\n\n"
+}
+
+// ConvertText converts blocks with indentation to plain text
+func ConvertText(blocks []BlockWithIndent, linkURLs bool) string {
+	return NewTextRenderer(linkURLs).Render(NewNodes(blocks))
+}
+
+// NewTextRenderer creates a Renderer that writes plain text without any
+// markup. Lists keep their indentation and bullets and blocks are separated
+// by blank lines. linkURLs appends the target of links in parentheses.
+func NewTextRenderer(linkURLs bool) *Renderer {
+	richText := FormatRichTextPlain
+	if linkURLs {
+		richText = FormatRichTextPlainWithURLs
+	}
+
+	return &Renderer{
+		blocks: map[notionapi.BlockType]BlockRenderer{
+			notionapi.BlockTypeHeading1:         renderTextBlock,
+			notionapi.BlockTypeHeading2:         renderTextBlock,
+			notionapi.BlockTypeHeading3:         renderTextBlock,
+			notionapi.BlockTypeParagraph:        renderParagraph,
+			notionapi.BlockTypeBulletedListItem: renderTextListItem("- "),
+			notionapi.BlockTypeToggle:           renderTextListItem("- "),
+			notionapi.BlockTypeNumberedListItem: renderTextListItem("1. "),
+			notionapi.BlockTypeToDo:             renderTextToDo,
+			notionapi.BlockTypeCode:             renderTextCode,
+			notionapi.BlockTypeQuote:            renderTextBlock,
+			notionapi.BlockTypeCallout:          renderTextCallout,
+		},
+		richText:      richText,
+		truncated:     "- (truncated: nested blocks omitted)",
+		separateLists: true,
+	}
+}
+
+// renderTextBlock writes the text of a block as a paragraph
+func renderTextBlock(r *Renderer, node *Node, depth int) string {
+	return joinLines(r.RichText(node.Text), "\n") + "\n\n" + r.Children(node, depth+1)
+}
+
+// renderTextListItem returns a renderer for list items with the given bullet
+func renderTextListItem(bullet string) BlockRenderer {
+	return func(r *Renderer, node *Node, depth int) string {
+		indent := Indent(depth)
+		text := joinLines(r.RichText(node.Text), "\n"+indent+"  ")
+		return indent + bullet + text + "\n" + r.Children(node, depth+1)
+	}
+}
+
+func renderTextToDo(r *Renderer, node *Node, depth int) string {
+	checkbox := "[ ] "
+	if node.Checked {
+		checkbox = "[x] "
+	}
+	return renderTextListItem("- "+checkbox)(r, node, depth)
+}
+
+func renderTextCode(r *Renderer, node *Node, depth int) string {
+	return PlainText(node.Text) + "\n\n"
+}
+
+func renderTextCallout(r *Renderer, node *Node, depth int) string {
+	return calloutPrefix(node) + renderTextBlock(r, node, depth)
+}
+
+// FormatRichTextPlain converts rich text to plain text without annotations or links
+func FormatRichTextPlain(spans []Span) string {
+	return PlainText(spans)
+}
+
+// FormatRichTextPlainWithURLs converts rich text to plain text, appending
+// the target of each link in parentheses unless it is the link text itself
+func FormatRichTextPlainWithURLs(spans []Span) string {
+	var result strings.Builder
+	for _, span := range spans {
+		result.WriteString(span.Text)
+		if span.Href != "" && span.Href != span.Text {
+			result.WriteString(" (" + span.Href + ")")
+		}
+	}
+	return result.String()
+}
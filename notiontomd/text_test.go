@@ -0,0 +1,58 @@
+package notiontomd
+
+import (
+	"testing"
+)
+
+func TestConvertText(t *testing.T) {
+	result := ConvertText(sampleBlocks(), false)
+	expected := "Title\n\n" +
+		"Some bold and code with a link\n\n" +
+		"- Parent\n" +
+		"  1. Child\n" +
+		"- Sibling\n\n" +
+		"fmt.Println(1)\n\n" +
+		"Quoted\n\n" +
+		"Note\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertTextLinkURLs(t *testing.T) {
+	result := ConvertText(sampleBlocks()[1:2], true)
+	expected := "Some bold and code with a link (https://example.com)\n\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestConvertTextTruncated(t *testing.T) {
+	blocks := []BlockWithIndent{
+		{Block: createBulletedListBlock("block-1", "Deep", true), Indent: 0, Truncated: true},
+	}
+
+	result := ConvertText(blocks, false)
+	expected := "- Deep\n  - (truncated: nested blocks omitted)\n"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestFormatRichTextPlainWithURLs(t *testing.T) {
+	spans := []Span{
+		{Type: "text", Text: "https://example.com", Href: "https://example.com"},
+		{Type: "text", Text: " and "},
+		{Type: "text", Text: "docs", Bold: true, Href: "https://example.com/docs"},
+	}
+
+	result := FormatRichTextPlainWithURLs(spans)
+	expected := "https://example.com and docs (https://example.com/docs)"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/syou6162/notion-to-md/internal/atomicfile"
+)
+
+// writeOutput writes content to path, or to stdout when path is empty
+func writeOutput(path string, content string) error {
+	if path == "" {
+		_, err := fmt.Fprint(os.Stdout, content)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
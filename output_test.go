@@ -0,0 +1,23 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteOutputCreatesDirectories(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "dir", "page.md")
+
+	if err := writeOutput(path, "# Title\n"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if string(data) != "# Title\n" {
+		t.Errorf("Expected %q, got %q", "# Title\n", string(data))
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+const (
+	// shutdownTimeout bounds how long serve waits for requests in flight when stopped
+	shutdownTimeout = 10 * time.Second
+	// readHeaderTimeout bounds how long a client may take to send the request headers
+	readHeaderTimeout = 10 * time.Second
+	// defaultServedPages is how many converted pages are kept in memory by default
+	defaultServedPages = 256
+)
+
+var serveCommand = &command{
+	name:    "serve",
+	usage:   "serve [flags]",
+	summary: "Serve pages as Markdown, HTML or JSON over HTTP",
+	description: "Start an HTTP server that fetches and converts pages on request:\n\n" +
+		"  GET /pages/{id}.md    Markdown, with --preset and the other rendering flags\n" +
+		"  GET /pages/{id}.html  HTML\n" +
+		"  GET /pages/{id}.json  the block tree as JSON\n\n" +
+		"Every request fetches the page metadata. Up to --max-pages converted pages are kept in memory\n" +
+		"until the page is edited, and responses carry an ETag and Last-Modified based on its last edited\n" +
+		"time, so conditional requests of unchanged pages are answered with 304 Not Modified. Pages with\n" +
+		"files hosted by Notion are only kept, and their ETags only valid, until the signed file URLs\n" +
+		"expire, and get no Last-Modified. Pages edited in the last two minutes are neither kept nor\n" +
+		"validated, since Notion only updates the last edited time once a minute. --format is ignored.",
+	examples: []string{
+		"notion-to-md serve",
+		"notion-to-md serve --addr :8080 --preset hugo",
+		"curl http://localhost:8080/pages/cec15681-9083-4e1f-a0ae-72d268507aab.md",
+	},
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var tokens tokenOptions
+		var render renderOptions
+		var cacheOpts cacheOptions
+		var slugName string
+		var addr string
+		var maxPages int
+		tokens.register(fs)
+		render.register(fs)
+		cacheOpts.register(fs)
+		fs.StringVar(&slugName, "slug", string(slugTitle), "file name `strategy` of links between pages: title, ascii, title-id or id")
+		fs.StringVar(&addr, "addr", "localhost:8080", "listen on `ADDRESS`")
+		fs.IntVar(&maxPages, "max-pages", defaultServedPages, "keep at most `N` converted pages in memory")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 0 {
+				return errUsage
+			}
+			if maxPages < 1 {
+				return errors.New("--max-pages must be positive")
+			}
+			render.format = "markdown"
+			if err := render.validate(); err != nil {
+				return err
+			}
+			slug, err := parseSlugStrategy(slugName)
+			if err != nil {
+				return err
+			}
+
+			client, err := tokens.newClient()
+			if err != nil {
+				return err
+			}
+			cache, err := cacheOpts.open()
+			if err != nil {
+				return err
+			}
+
+			// Presets only apply to Markdown
+			htmlRender, jsonRender := render, render
+			htmlRender.format, htmlRender.preset = "html", ""
+			jsonRender.format, jsonRender.preset = "json", ""
+			names := newPageNames(slug, render.extension())
+			converters := map[string]*notiontomd.Converter{
+				".md":   render.newConverter(client, cache, names),
+				".html": htmlRender.newConverter(client, cache, names),
+				".json": jsonRender.newConverter(client, cache, names),
+			}
+			s := newPageServer(converters[".md"].PageInfo, func(ctx context.Context, info notiontomd.PageInfo, ext string) (notiontomd.Document, error) {
+				return converters[ext].ConvertPageInfo(ctx, info)
+			}, render.fingerprint()+" slug="+string(slug))
+			s.maxPages = maxPages
+
+			server := &http.Server{Addr: addr, Handler: s.handler(), ReadHeaderTimeout: readHeaderTimeout}
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+				defer cancel()
+				server.Shutdown(shutdownCtx)
+			}()
+
+			fmt.Fprintf(os.Stderr, "Serving pages on http://%s/pages/\n", addr)
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		}
+	},
+}
+
+// servedTypes maps the extensions served under /pages/ to their content type
+var servedTypes = map[string]string{
+	".md":   "text/markdown; charset=utf-8",
+	".html": "text/html; charset=utf-8",
+	".json": "application/json",
+}
+
+// servedPage is a converted page kept until the page is edited or, when it
+// holds files hosted by Notion, until their signed URLs expire
+type servedPage struct {
+	key     string
+	edited  time.Time
+	content string
+	// files is set when the page holds files hosted by Notion, which expire
+	// at expires. A zero expires means the expiry is unknown.
+	files   bool
+	expires time.Time
+}
+
+// pageServer converts pages on request and keeps the latest conversion of
+// the most recently requested pages
+type pageServer struct {
+	pageInfo func(ctx context.Context, id string) (notiontomd.PageInfo, error)
+	convert  func(ctx context.Context, info notiontomd.PageInfo, ext string) (notiontomd.Document, error)
+	// tag identifies the rendering options in ETags, so that restarting
+	// with other options does not validate stale copies
+	tag string
+	// maxPages is the number of converted pages kept in memory
+	maxPages int
+	now      func() time.Time
+
+	mu sync.Mutex
+	// recent holds the kept pages, most recently requested first
+	recent *list.List
+	pages  map[string]*list.Element
+}
+
+// newPageServer creates a server. pageInfo fetches the metadata of a page,
+// convert renders it for an extension of servedTypes and options describes
+// the rendering options.
+func newPageServer(pageInfo func(ctx context.Context, id string) (notiontomd.PageInfo, error), convert func(ctx context.Context, info notiontomd.PageInfo, ext string) (notiontomd.Document, error), options string) *pageServer {
+	sum := sha256.Sum256([]byte(options))
+	return &pageServer{
+		pageInfo: pageInfo,
+		convert:  convert,
+		tag:      hex.EncodeToString(sum[:4]),
+		maxPages: defaultServedPages,
+		now:      time.Now,
+		recent:   list.New(),
+		pages:    make(map[string]*list.Element),
+	}
+}
+
+// handler returns the HTTP handler of the server
+func (s *pageServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pages/{file}", s.servePage)
+	return mux
+}
+
+// servePage answers GET /pages/{id}.{ext}
+func (s *pageServer) servePage(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	ext := path.Ext(file)
+	contentType, ok := servedTypes[ext]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported extension %q: use .md, .html or .json", ext), http.StatusNotFound)
+		return
+	}
+	pageID, err := notiontomd.ExtractBlockID(strings.TrimSuffix(file, ext))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	info, err := s.pageInfo(r.Context(), string(pageID))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+	// The version identifies the conversion of an unchanged page; ETags of
+	// pages with files also carry the expiry of their URLs. Recently edited
+	// pages may change without a new version, so they are not validated.
+	version := fmt.Sprintf("%s-%s-%d", s.tag, ext[1:], info.LastEditedTime.Unix())
+	settled := s.settled(info)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etag, ok := s.matchingETag(r.Header.Get("If-None-Match"), version); ok && settled {
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	page, err := s.content(r.Context(), info, ext)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+	// Pages with files are not validated by time, since their URLs expire
+	// without the page being edited
+	modified := info.LastEditedTime
+	if page.files || !settled {
+		modified = time.Time{}
+	}
+	if etag := s.etag(version, page); etag != "" && settled {
+		w.Header().Set("ETag", etag)
+	}
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, "", modified, strings.NewReader(page.content))
+}
+
+// etag returns the ETag of a converted page, or "" when its file URLs are
+// about to expire and it must not be reused
+func (s *pageServer) etag(version string, page servedPage) string {
+	if !page.files {
+		return `"` + version + `"`
+	}
+	if !s.fresh(page.expires) {
+		return ""
+	}
+	return fmt.Sprintf(`"%s-%d"`, version, page.expires.Unix())
+}
+
+// settled reports whether the page was edited long enough ago that its last
+// edited time changes with every further edit
+func (s *pageServer) settled(info notiontomd.PageInfo) bool {
+	return s.now().Sub(info.LastEditedTime) >= notiontomd.EditSettleTime
+}
+
+// fresh reports whether signed file URLs expiring at expires can still be served
+func (s *pageServer) fresh(expires time.Time) bool {
+	return s.now().Before(expires.Add(-notiontomd.FileURLMargin))
+}
+
+// content returns the page converted for ext, converting it again when it
+// was edited since the previous conversion, was edited recently or its file
+// URLs expire
+func (s *pageServer) content(ctx context.Context, info notiontomd.PageInfo, ext string) (servedPage, error) {
+	key := string(info.ID) + ext
+	settled := s.settled(info)
+	if page, ok := s.lookup(key); ok && settled && page.edited.Equal(info.LastEditedTime) && (!page.files || s.fresh(page.expires)) {
+		return page, nil
+	}
+
+	doc, err := s.convert(ctx, info, ext)
+	if err != nil {
+		return servedPage{}, err
+	}
+	blocks := make([]notionapi.Block, len(doc.Blocks))
+	for i, bwi := range doc.Blocks {
+		blocks[i] = bwi.Block
+	}
+	page := servedPage{key: key, edited: info.LastEditedTime, content: doc.Content}
+	page.expires, page.files = notiontomd.FilesExpireAt(blocks)
+	if settled && (!page.files || s.fresh(page.expires)) {
+		s.store(page)
+	}
+	return page, nil
+}
+
+// lookup returns the kept page for key and marks it as recently requested
+func (s *pageServer) lookup(key string) (servedPage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.pages[key]
+	if !ok {
+		return servedPage{}, false
+	}
+	s.recent.MoveToFront(elem)
+	return elem.Value.(servedPage), true
+}
+
+// store keeps a converted page, dropping the least recently requested
+// pages beyond maxPages
+func (s *pageServer) store(page servedPage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.pages[page.key]; ok {
+		elem.Value = page
+		s.recent.MoveToFront(elem)
+	} else {
+		s.pages[page.key] = s.recent.PushFront(page)
+	}
+	for s.recent.Len() > s.maxPages {
+		oldest := s.recent.Back()
+		s.recent.Remove(oldest)
+		delete(s.pages, oldest.Value.(servedPage).key)
+	}
+}
+
+// matchingETag returns the ETag of an If-None-Match header that is still
+// valid for the page version. ETags with a file expiry are only valid until
+// the file URLs expire. "*" matches without naming an ETag.
+func (s *pageServer) matchingETag(header, version string) (string, bool) {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" {
+			return "", true
+		}
+		suffix, ok := strings.CutPrefix(candidate, `"`+version)
+		if !ok {
+			continue
+		}
+		if suffix == `"` {
+			return candidate, true
+		}
+		expiry, ok := strings.CutPrefix(strings.TrimSuffix(suffix, `"`), "-")
+		if unix, err := strconv.ParseInt(expiry, 10, 64); ok && err == nil && strings.HasSuffix(suffix, `"`) && s.fresh(time.Unix(unix, 0)) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// errorStatus returns the response status for an error of the Notion API.
+// Pages that do not exist or are not shared with the integration are not
+// found; other failures are reported as a bad gateway.
+func errorStatus(err error) int {
+	var apiErr *notionapi.Error
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusBadGateway
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+const servedPageID = "cec15681-9083-4e1f-a0ae-72d268507aab"
+
+// Helper function to create a page server over one page, counting conversions
+func createPageServer(edited *time.Time, conversions *int) *httptest.Server {
+	return httptest.NewServer(newTestPageServer(edited, conversions, nil).handler())
+}
+
+// Helper function to create a page server whose page holds blocks
+func newTestPageServer(edited *time.Time, conversions *int, blocks []notiontomd.BlockWithIndent) *pageServer {
+	return newPageServer(
+		func(ctx context.Context, id string) (notiontomd.PageInfo, error) {
+			if id != servedPageID {
+				return notiontomd.PageInfo{}, fmt.Errorf("failed to get page info: %w", &notionapi.Error{Status: http.StatusNotFound, Message: "not found"})
+			}
+			return notiontomd.PageInfo{ID: notionapi.BlockID(id), LastEditedTime: *edited}, nil
+		},
+		func(ctx context.Context, info notiontomd.PageInfo, ext string) (notiontomd.Document, error) {
+			*conversions++
+			if ext == ".json" {
+				return notiontomd.Document{}, errors.New("fetch failed")
+			}
+			return notiontomd.Document{
+				Content: fmt.Sprintf("%s %s", ext, info.LastEditedTime.Format(time.RFC3339)),
+				Blocks:  blocks,
+			}, nil
+		},
+		"format=markdown")
+}
+
+// Helper function to create an image block hosted by Notion
+func createHostedImageBlock(expires time.Time) notiontomd.BlockWithIndent {
+	return notiontomd.BlockWithIndent{Block: &notionapi.ImageBlock{
+		BasicBlock: notionapi.BasicBlock{Object: notionapi.ObjectTypeBlock, Type: notionapi.BlockTypeImage},
+		Image: notionapi.Image{
+			Type: notionapi.FileTypeFile,
+			File: &notionapi.FileObject{URL: "https://files.example.com/image.png", ExpiryTime: &expires},
+		},
+	}}
+}
+
+// Helper function to send a GET request with an optional If-None-Match header
+func getPage(t *testing.T, url, etag string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return resp, string(body)
+}
+
+func TestPageServer(t *testing.T) {
+	edited := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := edited.Add(time.Hour)
+	conversions := 0
+	s := newTestPageServer(&edited, &conversions, nil)
+	s.now = func() time.Time { return now }
+	server := httptest.NewServer(s.handler())
+	defer server.Close()
+	url := server.URL + "/pages/" + servedPageID + ".md"
+
+	resp, body := getPage(t, url, "")
+	if resp.StatusCode != http.StatusOK || body != ".md 2024-01-01T00:00:00Z" {
+		t.Fatalf("Expected the converted page, got %d %q", resp.StatusCode, body)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/markdown; charset=utf-8" {
+		t.Errorf("Expected %q, got %q", "text/markdown; charset=utf-8", contentType)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+
+	// An unchanged page is validated without converting it
+	resp, _ = getPage(t, url, etag)
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected %d, got %d", http.StatusNotModified, resp.StatusCode)
+	}
+	// and served from memory without a matching ETag
+	getPage(t, url, "")
+	if conversions != 1 {
+		t.Errorf("Expected 1 conversion, got %d", conversions)
+	}
+
+	// An edited page is converted again with a new ETag
+	edited = edited.Add(time.Minute)
+	resp, body = getPage(t, url, etag)
+	if resp.StatusCode != http.StatusOK || body != ".md 2024-01-01T00:01:00Z" {
+		t.Errorf("Expected the edited page, got %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("ETag") == etag {
+		t.Error("Expected the ETag to change")
+	}
+	if conversions != 2 {
+		t.Errorf("Expected 2 conversions, got %d", conversions)
+	}
+
+	// A page edited within the last minute may change again without a new
+	// last edited time, so it is neither kept nor validated
+	edited = now.Add(-30 * time.Second).Truncate(time.Minute)
+	etag = fmt.Sprintf(`"%s-md-%d"`, s.tag, edited.Unix())
+	for range 2 {
+		resp, _ = getPage(t, url, etag)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+		if resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "" {
+			t.Errorf("Expected no validators, got ETag %q and Last-Modified %q", resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"))
+		}
+	}
+	if conversions != 4 {
+		t.Errorf("Expected 4 conversions, got %d", conversions)
+	}
+
+	// Once the minute has passed, the page is kept again
+	now = now.Add(notiontomd.EditSettleTime)
+	getPage(t, url, "")
+	getPage(t, url, "")
+	if conversions != 5 {
+		t.Errorf("Expected 5 conversions, got %d", conversions)
+	}
+}
+
+func TestPageServerErrors(t *testing.T) {
+	edited := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	conversions := 0
+	server := createPageServer(&edited, &conversions)
+	defer server.Close()
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{"/pages/" + servedPageID + ".html", http.StatusOK},
+		{"/pages/" + servedPageID + ".pdf", http.StatusNotFound},
+		{"/pages/not-a-page.md", http.StatusNotFound},
+		{"/pages/1b2c3d4e5f60718293a4b5c6d7e8f901.md", http.StatusNotFound},
+		{"/pages/" + servedPageID + ".json", http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		resp, _ := getPage(t, server.URL+tt.path, "")
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestPageServerFiles(t *testing.T) {
+	edited := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := edited.Add(time.Hour)
+	expires := now.Add(time.Hour)
+	conversions := 0
+	s := newTestPageServer(&edited, &conversions, []notiontomd.BlockWithIndent{createHostedImageBlock(expires)})
+	s.now = func() time.Time { return now }
+	server := httptest.NewServer(s.handler())
+	defer server.Close()
+	url := server.URL + "/pages/" + servedPageID + ".md"
+
+	resp, _ := getPage(t, url, "")
+	etag := resp.Header.Get("ETag")
+	if !strings.HasSuffix(etag, fmt.Sprintf("-%d\"", expires.Unix())) {
+		t.Errorf("Expected the ETag to carry the file expiry, got %q", etag)
+	}
+	if modified := resp.Header.Get("Last-Modified"); modified != "" {
+		t.Errorf("Expected no Last-Modified, got %q", modified)
+	}
+
+	// The page is validated and kept while its file URLs are valid
+	if resp, _ = getPage(t, url, etag); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected %d, got %d", http.StatusNotModified, resp.StatusCode)
+	}
+	getPage(t, url, "")
+	if conversions != 1 {
+		t.Errorf("Expected 1 conversion, got %d", conversions)
+	}
+
+	// and converted again once they are about to expire
+	now = expires.Add(-notiontomd.FileURLMargin)
+	if resp, _ = getPage(t, url, etag); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if resp.Header.Get("ETag") != "" {
+		t.Errorf("Expected no ETag for expiring files, got %q", resp.Header.Get("ETag"))
+	}
+	if conversions != 2 {
+		t.Errorf("Expected 2 conversions, got %d", conversions)
+	}
+}
+
+func TestPageServerEvictsLeastRecentPages(t *testing.T) {
+	s := newPageServer(nil, nil, "")
+	s.maxPages = 2
+	for _, key := range []string{"a.md", "b.md"} {
+		s.store(servedPage{key: key})
+	}
+	s.lookup("a.md")
+	s.store(servedPage{key: "c.md"})
+
+	for key, expected := range map[string]bool{"a.md": true, "b.md": false, "c.md": true} {
+		if _, ok := s.lookup(key); ok != expected {
+			t.Errorf("%s: expected kept %t, got %t", key, expected, ok)
+		}
+	}
+}
+
+func TestPageServerMatchingETag(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newPageServer(nil, nil, "")
+	s.now = func() time.Time { return now }
+	valid := now.Add(time.Hour).Unix()
+	expiring := now.Add(notiontomd.FileURLMargin).Unix()
+
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{`"a-md-1"`, true},
+		{`"b", W/"a-md-1"`, true},
+		{`*`, true},
+		{fmt.Sprintf(`"a-md-1-%d"`, valid), true},
+		{fmt.Sprintf(`"a-md-1-%d"`, expiring), false},
+		{`"a-md-2"`, false},
+		{`"a-md-12"`, false},
+		{``, false},
+	}
+
+	for _, tt := range tests {
+		if _, result := s.matchingETag(tt.header, "a-md-1"); result != tt.expected {
+			t.Errorf("%q: expected %t, got %t", tt.header, tt.expected, result)
+		}
+	}
+}
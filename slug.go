@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+// slugStrategy selects how output file names are derived from pages
+type slugStrategy string
+
+const (
+	// slugTitle keeps letters of any script, so Japanese titles stay readable
+	slugTitle slugStrategy = "title"
+	// slugASCII keeps only ASCII letters and digits
+	slugASCII slugStrategy = "ascii"
+	// slugTitleID appends the page ID to the title slug
+	slugTitleID slugStrategy = "title-id"
+	// slugID uses the page ID only
+	slugID slugStrategy = "id"
+)
+
+// maxSlugBytes keeps file names well below the 255 byte limit of common file systems
+const maxSlugBytes = 120
+
+// parseSlugStrategy validates a slug strategy given on the command line
+func parseSlugStrategy(s string) (slugStrategy, error) {
+	switch strategy := slugStrategy(s); strategy {
+	case slugTitle, slugASCII, slugTitleID, slugID:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unsupported slug strategy %q", s)
+}
+
+// pageSlug returns the file name of a page without extension. The page ID
+// is used when the title yields an empty slug.
+func pageSlug(info notiontomd.PageInfo, strategy slugStrategy) string {
+	id := strings.ReplaceAll(string(info.ID), "-", "")
+
+	var slug string
+	switch strategy {
+	case slugID:
+		return id
+	case slugASCII:
+		slug = slugify(info.Title, true)
+	case slugTitleID:
+		if slug = slugify(info.Title, false); slug != "" {
+			slug += "-" + id
+		}
+	default:
+		slug = slugify(info.Title, false)
+	}
+
+	if slug == "" {
+		return id
+	}
+	return slug
+}
+
+// slugify lowercases the title and replaces every run of characters other
+// than letters and digits with a single hyphen. Full-width alphanumerics
+// are folded to ASCII; other letters are dropped when asciiOnly is set.
+func slugify(title string, asciiOnly bool) string {
+	var result strings.Builder
+	pendingHyphen := false
+
+	for _, r := range title {
+		r = unicode.ToLower(foldWidth(r))
+
+		keep := false
+		if r < utf8.RuneSelf {
+			keep = unicode.IsLetter(r) || unicode.IsDigit(r)
+		} else if !asciiOnly {
+			// Mn keeps combining marks such as the voiced sound mark in Japanese
+			keep = unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+		}
+
+		if !keep {
+			pendingHyphen = true
+			continue
+		}
+		if result.Len()+utf8.RuneLen(r)+1 > maxSlugBytes {
+			break
+		}
+		if pendingHyphen && result.Len() > 0 {
+			result.WriteByte('-')
+		}
+		pendingHyphen = false
+		result.WriteRune(r)
+	}
+
+	return result.String()
+}
+
+// foldWidth maps full-width ASCII variants (e.g. "Ａ", "１") to ASCII
+func foldWidth(r rune) rune {
+	if r >= 0xFF01 && r <= 0xFF5E {
+		return r - 0xFEE0
+	}
+	return r
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name      string
+		title     string
+		asciiOnly bool
+		expected  string
+	}{
+		{
+			name:     "ASCII title",
+			title:    "Hello, World!",
+			expected: "hello-world",
+		},
+		{
+			name:     "Japanese title",
+			title:    "設計ドキュメント（ドラフト）",
+			expected: "設計ドキュメント-ドラフト",
+		},
+		{
+			name:     "Mixed title with ideographic space",
+			title:    "Go言語　入門 2024",
+			expected: "go言語-入門-2024",
+		},
+		{
+			name:     "Full-width alphanumerics",
+			title:    "ＡＰＩ設計１",
+			expected: "api設計1",
+		},
+		{
+			name:      "ASCII only drops Japanese",
+			title:     "Go言語入門",
+			asciiOnly: true,
+			expected:  "go",
+		},
+		{
+			name:     "Only punctuation",
+			title:    "!!! ???",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := slugify(tt.title, tt.asciiOnly)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSlugifyTruncatesLongTitles(t *testing.T) {
+	result := slugify(strings.Repeat("長い", 100), false)
+	if len(result) > maxSlugBytes {
+		t.Errorf("Expected at most %d bytes, got %d", maxSlugBytes, len(result))
+	}
+}
+
+func TestPageSlug(t *testing.T) {
+	info := notiontomd.PageInfo{
+		ID:    notionapi.BlockID("cec15681-9083-4e1f-a0ae-72d268507aab"),
+		Title: "議事録 Weekly",
+	}
+	untitled := notiontomd.PageInfo{ID: info.ID}
+
+	tests := []struct {
+		name     string
+		info     notiontomd.PageInfo
+		strategy slugStrategy
+		expected string
+	}{
+		{"Title", info, slugTitle, "議事録-weekly"},
+		{"ASCII", info, slugASCII, "weekly"},
+		{"Title with ID", info, slugTitleID, "議事録-weekly-cec1568190834e1fa0ae72d268507aab"},
+		{"ID", info, slugID, "cec1568190834e1fa0ae72d268507aab"},
+		{"Empty title falls back to ID", untitled, slugTitle, "cec1568190834e1fa0ae72d268507aab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pageSlug(tt.info, tt.strategy)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+// pruneMode selects what happens to the files of pages that were removed or moved
+type pruneMode string
+
+const (
+	pruneArchive pruneMode = "archive"
+	pruneDelete  pruneMode = "delete"
+	pruneKeep    pruneMode = "keep"
+)
+
+// archiveDir is the directory inside the output directory that receives archived files
+const archiveDir = ".archive"
+
+// parsePruneMode validates a prune mode given on the command line
+func parsePruneMode(s string) (pruneMode, error) {
+	switch mode := pruneMode(s); mode {
+	case pruneArchive, pruneDelete, pruneKeep:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unsupported prune mode %q", s)
+}
+
+// syncState compares the pages of a sync with the manifest of the previous one
+type syncState struct {
+	outDir   string
+	previous *manifest
+	// force exports every page again. The previous manifest is still used
+	// to prune removed pages and to keep the names of pages.
+	force bool
+
+	mu        sync.Mutex
+	current   *manifest
+	exported  int
+	unchanged int
+}
+
+// newSyncState creates the state of a sync into outDir
+func newSyncState(outDir string, previous *manifest, options string) *syncState {
+	return &syncState{
+		outDir:   filepath.Clean(outDir),
+		previous: previous,
+		current:  newManifest(options),
+	}
+}
+
+// relPath returns path relative to the output directory with forward slashes
+func (s *syncState) relPath(path string) string {
+	rel, err := filepath.Rel(s.outDir, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// skip reports whether the page is unchanged since the previous sync and its
+// file is still intact at path. Skipped pages are carried over into the new manifest.
+func (s *syncState) skip(info notiontomd.PageInfo, path string) (manifestEntry, bool) {
+	if s.force || s.previous.Options != s.current.Options {
+		return manifestEntry{}, false
+	}
+	entry, ok := s.previous.Pages[info.ID]
+	if !ok || !entry.LastEditedTime.Equal(info.LastEditedTime) || entry.Path != s.relPath(path) {
+		return manifestEntry{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || contentHash(data) != entry.Hash {
+		return manifestEntry{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current.Pages[info.ID] = entry
+	s.unchanged++
+	return entry, true
+}
+
+// record adds an exported page to the new manifest
+func (s *syncState) record(info notiontomd.PageInfo, path string, content string, children []notionapi.BlockID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current.Pages[info.ID] = manifestEntry{
+		Title:          info.Title,
+		LastEditedTime: info.LastEditedTime,
+		Path:           s.relPath(path),
+		Hash:           contentHash([]byte(content)),
+		Children:       children,
+	}
+	s.exported++
+}
+
+// keepUnseen carries over the entries of pages that were not visited, so a
+// failed sync does not forget pages it could not reach
+func (s *syncState) keepUnseen() {
+	for id, entry := range s.previous.Pages {
+		if _, ok := s.current.Pages[id]; !ok {
+			s.current.Pages[id] = entry
+		}
+	}
+}
+
+// prune deletes or archives the files of pages that were removed since the
+// previous sync or moved to another path, and returns the number of files handled
+func (s *syncState) prune(mode pruneMode) (int, error) {
+	if mode == pruneKeep {
+		return 0, nil
+	}
+
+	claimed := make(map[string]bool)
+	for _, entry := range s.current.Pages {
+		claimed[entry.Path] = true
+	}
+
+	pruned := 0
+	var errs []error
+	for _, entry := range s.previous.Pages {
+		if claimed[entry.Path] {
+			continue
+		}
+
+		path := filepath.Join(s.outDir, filepath.FromSlash(entry.Path))
+		var err error
+		switch mode {
+		case pruneDelete:
+			err = os.Remove(path)
+		case pruneArchive:
+			dest := filepath.Join(s.outDir, archiveDir, filepath.FromSlash(entry.Path))
+			if err = os.MkdirAll(filepath.Dir(dest), 0o755); err == nil {
+				err = os.Rename(path, unusedPath(dest))
+			}
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to prune %s: %w", entry.Path, err))
+			continue
+		}
+		removeEmptyDirs(filepath.Dir(path), s.outDir)
+		pruned++
+	}
+
+	return pruned, errors.Join(errs...)
+}
+
+// unusedPath returns path, or when a file exists there, the first of
+// name-1.ext, name-2.ext, ... that does not exist, so archived files never
+// replace earlier ones
+func unusedPath(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for n := 1; ; n++ {
+		if _, err := os.Lstat(path); errors.Is(err, fs.ErrNotExist) {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+}
+
+// removeEmptyDirs removes dir and its parents up to (but excluding) root while they are empty
+func removeEmptyDirs(dir, root string) {
+	for dir != root && dir != "." && dir != string(filepath.Separator) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
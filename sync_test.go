@@ -0,0 +1,262 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+func TestManifestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	edited := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+
+	m := newManifest("format=markdown")
+	m.Pages["page-1"] = manifestEntry{
+		Title:          "Page",
+		LastEditedTime: edited,
+		Path:           "page.md",
+		Hash:           contentHash([]byte("content")),
+		Children:       []notionapi.BlockID{"child-1"},
+	}
+	if err := m.save(dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	loaded, err := loadManifest(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	entry, ok := loaded.Pages["page-1"]
+	if !ok {
+		t.Fatal("Expected page-1 in loaded manifest")
+	}
+	if loaded.Options != "format=markdown" {
+		t.Errorf("Expected options %q, got %q", "format=markdown", loaded.Options)
+	}
+	if !entry.LastEditedTime.Equal(edited) || entry.Path != "page.md" || len(entry.Children) != 1 {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+}
+
+func TestLoadManifestMissing(t *testing.T) {
+	m, err := loadManifest(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(m.Pages) != 0 {
+		t.Errorf("Expected empty manifest, got %d pages", len(m.Pages))
+	}
+}
+
+func TestSyncStateSkip(t *testing.T) {
+	dir := t.TempDir()
+	edited := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+	path := filepath.Join(dir, "page.md")
+	if err := os.WriteFile(path, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := newManifest("opts")
+	previous.Pages["page-1"] = manifestEntry{
+		LastEditedTime: edited,
+		Path:           "page.md",
+		Hash:           contentHash([]byte("content")),
+	}
+	info := notiontomd.PageInfo{ID: "page-1", LastEditedTime: edited}
+
+	tests := []struct {
+		name     string
+		options  string
+		info     notiontomd.PageInfo
+		path     string
+		expected bool
+	}{
+		{"Unchanged", "opts", info, path, true},
+		{"Edited", "opts", notiontomd.PageInfo{ID: "page-1", LastEditedTime: edited.Add(time.Minute)}, path, false},
+		{"Moved", "opts", info, filepath.Join(dir, "sub", "page.md"), false},
+		{"Options changed", "other", info, path, false},
+		{"New page", "opts", notiontomd.PageInfo{ID: "page-2", LastEditedTime: edited}, path, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newSyncState(dir, previous, tt.options)
+			_, skipped := state.skip(tt.info, tt.path)
+			if skipped != tt.expected {
+				t.Errorf("Expected skip %v, got %v", tt.expected, skipped)
+			}
+		})
+	}
+}
+
+func TestSyncStateSkipModifiedFile(t *testing.T) {
+	dir := t.TempDir()
+	edited := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+	path := filepath.Join(dir, "page.md")
+	if err := os.WriteFile(path, []byte("edited locally"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := newManifest("opts")
+	previous.Pages["page-1"] = manifestEntry{
+		LastEditedTime: edited,
+		Path:           "page.md",
+		Hash:           contentHash([]byte("content")),
+	}
+
+	state := newSyncState(dir, previous, "opts")
+	if _, skipped := state.skip(notiontomd.PageInfo{ID: "page-1", LastEditedTime: edited}, path); skipped {
+		t.Error("Expected a locally modified file to be exported again")
+	}
+}
+
+func TestSyncStatePrune(t *testing.T) {
+	for _, mode := range []pruneMode{pruneArchive, pruneDelete, pruneKeep} {
+		t.Run(string(mode), func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range []string{"kept.md", "removed.md", filepath.Join("old", "moved.md")} {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			previous := newManifest("opts")
+			previous.Pages["kept"] = manifestEntry{Path: "kept.md"}
+			previous.Pages["removed"] = manifestEntry{Path: "removed.md"}
+			previous.Pages["moved"] = manifestEntry{Path: "old/moved.md"}
+
+			state := newSyncState(dir, previous, "opts")
+			state.record(notiontomd.PageInfo{ID: "kept"}, filepath.Join(dir, "kept.md"), "kept.md", nil)
+			state.record(notiontomd.PageInfo{ID: "moved"}, filepath.Join(dir, "new", "moved.md"), "moved", nil)
+
+			pruned, err := state.prune(mode)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expectedPruned := 2
+			if mode == pruneKeep {
+				expectedPruned = 0
+			}
+			if pruned != expectedPruned {
+				t.Errorf("Expected %d pruned files, got %d", expectedPruned, pruned)
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, "kept.md")); err != nil {
+				t.Errorf("Expected kept.md to remain: %v", err)
+			}
+
+			_, err = os.Stat(filepath.Join(dir, "removed.md"))
+			if removed := os.IsNotExist(err); removed != (mode != pruneKeep) {
+				t.Errorf("Unexpected state of removed.md: %v", err)
+			}
+
+			_, err = os.Stat(filepath.Join(dir, "old"))
+			if removed := os.IsNotExist(err); removed != (mode != pruneKeep) {
+				t.Errorf("Expected the emptied directory to be removed: %v", err)
+			}
+
+			_, err = os.Stat(filepath.Join(dir, archiveDir, "removed.md"))
+			if archived := err == nil; archived != (mode == pruneArchive) {
+				t.Errorf("Unexpected archive state of removed.md: %v", err)
+			}
+		})
+	}
+}
+
+func TestSyncStatePruneKeepsEarlierArchives(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, archiveDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"removed.md":                              "new",
+		filepath.Join(archiveDir, "removed.md"):   "archived before",
+		filepath.Join(archiveDir, "removed-1.md"): "archived twice before",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previous := newManifest("opts")
+	previous.Pages["removed"] = manifestEntry{Path: "removed.md"}
+	state := newSyncState(dir, previous, "opts")
+	if _, err := state.prune(pruneArchive); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for name, expected := range map[string]string{
+		"removed.md":   "archived before",
+		"removed-1.md": "archived twice before",
+		"removed-2.md": "new",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, archiveDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(data) != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, string(data))
+		}
+	}
+}
+
+func TestSyncStateForce(t *testing.T) {
+	dir := t.TempDir()
+	edited := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+	for name, content := range map[string]string{"kept.md": "content", "removed.md": "removed"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previous := newManifest("opts")
+	previous.Pages["kept"] = manifestEntry{LastEditedTime: edited, Path: "kept.md", Hash: contentHash([]byte("content"))}
+	previous.Pages["removed"] = manifestEntry{Path: "removed.md"}
+
+	state := newSyncState(dir, previous, "opts")
+	state.force = true
+	info := notiontomd.PageInfo{ID: "kept", LastEditedTime: edited}
+	if _, skipped := state.skip(info, filepath.Join(dir, "kept.md")); skipped {
+		t.Error("Expected a forced sync to export an unchanged page again")
+	}
+	state.record(info, filepath.Join(dir, "kept.md"), "content", nil)
+
+	// Pages removed from Notion are still pruned
+	pruned, err := state.prune(pruneDelete)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("Expected 1 pruned file, got %d", pruned)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "removed.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected removed.md to be deleted: %v", err)
+	}
+}
+
+func TestSyncStateKeepUnseen(t *testing.T) {
+	previous := newManifest("opts")
+	previous.Pages["page-1"] = manifestEntry{Path: "one.md"}
+	previous.Pages["page-2"] = manifestEntry{Path: "two.md"}
+
+	state := newSyncState(t.TempDir(), previous, "opts")
+	state.record(notiontomd.PageInfo{ID: "page-1"}, filepath.Join(state.outDir, "renamed.md"), "content", nil)
+	state.keepUnseen()
+
+	if state.current.Pages["page-1"].Path != "renamed.md" {
+		t.Errorf("Expected the new entry of page-1 to be kept, got %+v", state.current.Pages["page-1"])
+	}
+	if state.current.Pages["page-2"].Path != "two.md" {
+		t.Errorf("Expected page-2 to be carried over, got %+v", state.current.Pages["page-2"])
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+// defaultWatchInterval is how often pages are checked for changes by default
+const defaultWatchInterval = time.Minute
+
+var watchCommand = &command{
+	name:    "watch",
+	usage:   "watch [flags] <page-id-or-url>... | -",
+	summary: "Re-export pages whenever they are edited",
+	description: "Export the pages into --out-dir (default the current directory), then check their last edited\n" +
+		"time every --interval and export the pages that changed. Only the page metadata is fetched\n" +
+		"while nothing changes.\n\n" +
+		"After each round that exported a page, the --exec command is run with sh -c and the written\n" +
+		"files as its arguments (\"$@\"). Errors are reported and retried on the next round; stop\n" +
+		"watching with Ctrl-C.",
+	examples: []string{
+		"notion-to-md watch --out-dir docs cec15681-9083-4e1f-a0ae-72d268507aab",
+		"notion-to-md watch --interval 30s --preset hugo --out-dir content/posts --exec 'hugo --minify' - < pages.txt",
+	},
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var tokens tokenOptions
+		var render renderOptions
+		var cacheOpts cacheOptions
+		var output outputOptions
+		var interval time.Duration
+		var hook string
+		tokens.register(fs)
+		render.register(fs)
+		cacheOpts.register(fs)
+		output.register(fs)
+		fs.DurationVar(&interval, "interval", defaultWatchInterval, "how often to check the pages for changes")
+		fs.StringVar(&hook, "exec", "", "shell `command` run after pages were exported, with the written files as arguments")
+
+		return func(ctx context.Context, args []string) error {
+			inputs, err := collectInputs(args, os.Stdin)
+			if err != nil {
+				return err
+			}
+			if len(inputs) == 0 {
+				return errUsage
+			}
+			if err := render.validate(); err != nil {
+				return err
+			}
+			if interval <= 0 {
+				return errors.New("--interval must be positive")
+			}
+			slug, err := output.slugStrategy()
+			if err != nil {
+				return err
+			}
+
+			client, err := tokens.newClient()
+			if err != nil {
+				return err
+			}
+			cache, err := cacheOpts.open()
+			if err != nil {
+				return err
+			}
+
+			// One exporter names every page, so that pages with the same
+			// title are written to different files in every round
+			dir := output.dir()
+			names := newPageNames(slug, render.extension())
+			exporter := newPageExporter(render.newConverter(client, cache, names), names, 1, false)
+			exporter.attachments = render.attachmentDir(dir)
+			w := newPageWatcher(inputs, exporter.conv.PageInfo, func(ctx context.Context, info notiontomd.PageInfo) (string, error) {
+				result, err := exporter.export(ctx, info, names.claim(dir, info))
+				if err != nil {
+					return "", err
+				}
+				return result.Path, nil
+			})
+			w.claim = func(infos []notiontomd.PageInfo) {
+				exporter.claimPaths(dir, infos)
+			}
+			w.hook = hook
+
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			w.run(ctx, interval)
+			return nil
+		}
+	},
+}
+
+// pageWatcher exports pages again whenever their last edited time changes
+type pageWatcher struct {
+	inputs   []string
+	pageInfo func(ctx context.Context, input string) (notiontomd.PageInfo, error)
+	export   func(ctx context.Context, info notiontomd.PageInfo) (string, error)
+	// claim, if set, is called with the pages edited since the previous round
+	// before any of them is exported, so that their names do not depend on
+	// the order of the inputs
+	claim func(infos []notiontomd.PageInfo)
+	// hook is the shell command run after a round that exported pages
+	hook string
+	// log receives progress and errors, os.Stderr by default
+	log io.Writer
+
+	// edited is the last edited time of each input when it was last exported
+	edited map[string]time.Time
+}
+
+// newPageWatcher creates a watcher for inputs. pageInfo fetches the metadata
+// of an input and export writes a page, returning the path it was written to.
+func newPageWatcher(inputs []string, pageInfo func(ctx context.Context, input string) (notiontomd.PageInfo, error), export func(ctx context.Context, info notiontomd.PageInfo) (string, error)) *pageWatcher {
+	return &pageWatcher{
+		inputs:   inputs,
+		pageInfo: pageInfo,
+		export:   export,
+		log:      os.Stderr,
+		edited:   make(map[string]time.Time),
+	}
+}
+
+// run polls the pages every interval until ctx is canceled
+func (w *pageWatcher) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.round(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// round exports the pages edited since the previous round and runs the hook
+// when any page was written. It returns the written paths.
+func (w *pageWatcher) round(ctx context.Context) []string {
+	var inputs []string
+	var infos []notiontomd.PageInfo
+	for _, input := range w.inputs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		info, err := w.pageInfo(ctx, input)
+		if err != nil {
+			fmt.Fprintf(w.log, "Error: %s: %v\n", input, err)
+			continue
+		}
+		if edited, ok := w.edited[input]; ok && edited.Equal(info.LastEditedTime) {
+			continue
+		}
+		inputs = append(inputs, input)
+		infos = append(infos, info)
+	}
+	if w.claim != nil && len(infos) > 0 {
+		w.claim(infos)
+	}
+
+	var paths []string
+	for i, info := range infos {
+		if ctx.Err() != nil {
+			return paths
+		}
+		path, err := w.export(ctx, info)
+		if err != nil {
+			// The page is exported again on the next round
+			fmt.Fprintf(w.log, "Error: %s: %v\n", inputs[i], err)
+			continue
+		}
+		w.edited[inputs[i]] = info.LastEditedTime
+		fmt.Fprintf(w.log, "%s: exported to %s\n", inputs[i], path)
+		paths = append(paths, path)
+	}
+
+	if len(paths) > 0 && w.hook != "" {
+		if err := runHook(ctx, w.hook, paths); err != nil {
+			fmt.Fprintf(w.log, "Error: --exec: %v\n", err)
+		}
+	}
+	return paths
+}
+
+// runHook runs a shell command with the written paths as its arguments
+func runHook(ctx context.Context, hook string, paths []string) error {
+	cmd := exec.CommandContext(ctx, "sh", append([]string{"-c", hook, "sh"}, paths...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/syou6162/notion-to-md/notiontomd"
+)
+
+func TestPageWatcherRound(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	edited := map[string]time.Time{"a": base, "b": base}
+	failing := map[string]bool{}
+	var exported []string
+
+	w := newPageWatcher([]string{"a", "b"},
+		func(ctx context.Context, input string) (notiontomd.PageInfo, error) {
+			return notiontomd.PageInfo{ID: notionapi.BlockID(input), LastEditedTime: edited[input]}, nil
+		},
+		func(ctx context.Context, info notiontomd.PageInfo) (string, error) {
+			if failing[string(info.ID)] {
+				return "", errors.New("fetch failed")
+			}
+			exported = append(exported, string(info.ID))
+			return string(info.ID) + ".md", nil
+		})
+	w.log = io.Discard
+	ctx := context.Background()
+
+	// The first round exports every page
+	if paths := w.round(ctx); !reflect.DeepEqual(paths, []string{"a.md", "b.md"}) {
+		t.Errorf("Expected both pages to be exported, got %v", paths)
+	}
+
+	// Unchanged pages are not exported again
+	if paths := w.round(ctx); len(paths) != 0 {
+		t.Errorf("Expected no exports, got %v", paths)
+	}
+
+	// A page that fails is retried on the next round
+	edited["b"] = base.Add(time.Minute)
+	failing["b"] = true
+	if paths := w.round(ctx); len(paths) != 0 {
+		t.Errorf("Expected no exports, got %v", paths)
+	}
+	failing["b"] = false
+	if paths := w.round(ctx); !reflect.DeepEqual(paths, []string{"b.md"}) {
+		t.Errorf("Expected the edited page to be exported, got %v", paths)
+	}
+
+	if !reflect.DeepEqual(exported, []string{"a", "b", "b"}) {
+		t.Errorf("Expected %v, got %v", []string{"a", "b", "b"}, exported)
+	}
+}
+
+func TestPageWatcherNamesSameTitles(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	edited := map[string]time.Time{"bbbb": base, "aaaa": base}
+	names := newPageNames(slugTitle, ".md")
+	exporter := newPageExporter(nil, names, 1, false)
+
+	w := newPageWatcher([]string{"bbbb", "aaaa"},
+		func(ctx context.Context, input string) (notiontomd.PageInfo, error) {
+			return notiontomd.PageInfo{ID: notionapi.BlockID(input), Title: "Meeting notes", LastEditedTime: edited[input]}, nil
+		},
+		func(ctx context.Context, info notiontomd.PageInfo) (string, error) {
+			return names.claim("docs", info), nil
+		})
+	w.claim = func(infos []notiontomd.PageInfo) {
+		exporter.claimPaths("docs", infos)
+	}
+	w.log = io.Discard
+	ctx := context.Background()
+
+	// The page with the lowest ID gets the title path, whatever the input order
+	expected := []string{filepath.Join("docs", "meeting-notes-bbbb.md"), filepath.Join("docs", "meeting-notes.md")}
+	if paths := w.round(ctx); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+
+	// An edited page is written to the same file again
+	edited["bbbb"] = base.Add(time.Minute)
+	if paths := w.round(ctx); !reflect.DeepEqual(paths, expected[:1]) {
+		t.Errorf("Expected %v, got %v", expected[:1], paths)
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "hook.txt")
+
+	if err := runHook(context.Background(), `echo "$@" > `+out, []string{"a.md", "b.md"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read hook output: %v", err)
+	}
+	if string(data) != "a.md b.md\n" {
+		t.Errorf("Expected %q, got %q", "a.md b.md\n", string(data))
+	}
+
+	if err := runHook(context.Background(), "exit 3", nil); err == nil {
+		t.Error("Expected an error for a failing hook")
+	}
+}